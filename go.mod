@@ -1,6 +1,6 @@
 module github.com/openchami/schemas
 
-go 1.21
+go 1.24
 
 require github.com/invopop/jsonschema v0.12.0
 
@@ -10,5 +10,5 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
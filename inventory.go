@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/tabular"
+)
+
+const inventoryUsage = `usage:
+  schemas inventory export [-format csv|yaml] [-o file] [inventory.json]
+  schemas inventory import [-format csv|yaml] [-o file] [-request] [inventory.csv|inventory.yaml]`
+
+// inventoryCommand runs "inventory export", which converts a JSON array of
+// InventoryDetail or an InventoryDetailRequest to CSV or YAML, or "inventory
+// import", which converts them back to JSON.  Files default to stdin and
+// stdout, and the format defaults to the extension of the CSV or YAML file.
+// Export warns on stderr about fields that the rows cannot hold.
+func inventoryCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(inventoryUsage)
+	}
+	switch args[0] {
+	case "export":
+		return exportInventory(args[1:])
+	case "import":
+		return importInventory(args[1:])
+	default:
+		return fmt.Errorf("unknown inventory command %q\n%s", args[0], inventoryUsage)
+	}
+}
+
+func exportInventory(args []string) error {
+	fs := flag.NewFlagSet("inventory export", flag.ContinueOnError)
+	format := fs.String("format", "", "Output format, csv or yaml (default from the -o extension, or csv)")
+	output := fs.String("o", "", "Output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	details, err := decodeInventory(data)
+	if err != nil {
+		return err
+	}
+	for i, d := range details {
+		if dropped := tabular.Dropped(d); len(dropped) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s: not exported: %s\n", nodeName(i, d), strings.Join(dropped, ", "))
+		}
+	}
+
+	var buf bytes.Buffer
+	switch inventoryFormat(*format, *output) {
+	case "csv":
+		err = tabular.WriteCSV(&buf, details)
+	case "yaml":
+		err = tabular.WriteYAML(&buf, details)
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, buf.Bytes())
+}
+
+// nodeName identifies the i'th detail in messages.
+func nodeName(i int, d schemas.InventoryDetail) string {
+	switch {
+	case d.UUID != "":
+		return d.UUID
+	case d.Serial != "":
+		return d.Serial
+	default:
+		return fmt.Sprintf("node %d", i+1)
+	}
+}
+
+func importInventory(args []string) error {
+	fs := flag.NewFlagSet("inventory import", flag.ContinueOnError)
+	format := fs.String("format", "", "Input format, csv or yaml (default from the input extension, or csv)")
+	output := fs.String("o", "", "Output file (default stdout)")
+	request := fs.Bool("request", false, "Write an InventoryDetailRequest instead of a JSON array of InventoryDetail")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var details []schemas.InventoryDetail
+	switch inventoryFormat(*format, fs.Arg(0)) {
+	case "csv":
+		details, err = tabular.ReadCSV(bytes.NewReader(data))
+	case "yaml":
+		details, err = tabular.ReadYAML(bytes.NewReader(data))
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid inventory:\n%w", err)
+	}
+
+	var v interface{} = details
+	if *request {
+		v = schemas.NewInventoryRequest(details)
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, append(out, '\n'))
+}
+
+// inventoryFormat returns the explicit format, or the one implied by the
+// file's extension.
+func inventoryFormat(format, file string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return "csv"
+}
+
+// decodeInventory accepts either a JSON array of InventoryDetail or an
+// InventoryDetailRequest.
+func decodeInventory(data []byte) ([]schemas.InventoryDetail, error) {
+	var details []schemas.InventoryDetail
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &details); err != nil {
+			return nil, fmt.Errorf("failed to parse inventory: %w", err)
+		}
+		return details, nil
+	}
+	var r schemas.InventoryRequest
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory request: %w", err)
+	}
+	if err := r.ValidateHeader(); err != nil {
+		return nil, err
+	}
+	return r.InventoryDetailArray, nil
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func writeOutput(path string, data []byte) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 
 	"os"
 	"path/filepath"
@@ -13,17 +14,37 @@ import (
 	"github.com/openchami/schemas/schemas/csm"
 )
 
-type InventoryRequest struct {
-	Header               schemas.Envelope          `json:"header"`
-	InventoryDetailArray []schemas.InventoryDetail `json:"inventory_detail_array"`
-}
-
 func generateAndWriteSchemas(path string) {
+	reflector := &jsonschema.Reflector{Namer: schemas.SchemaTypeName}
 	schemas := map[string]interface{}{
 
-		"Component.json":              &csm.Component{},
-		"RedfishEndpoint.json":        &csm.RedfishEndpoint{},
-		"InventoryDetailRequest.json": &InventoryRequest{},
+		"Component.json":               &csm.Component{},
+		"RedfishEndpoint.json":         &csm.RedfishEndpoint{},
+		"InventoryDetailRequest.json":  &schemas.InventoryRequest{},
+		"InventoryDetailResponse.json": &schemas.InventoryResponse{},
+		"InventoryDiff.json":           &schemas.InventoryDiff{},
+		"InventoryMerge.json":          &schemas.MergedInventory{},
+		"InventoryMergePolicy.json":    &schemas.MergePolicy{},
+
+		"ComponentBulkUpdate.json":         &csm.ComponentBulkUpdate{},
+		"ComponentBulkUpdateResponse.json": &csm.ComponentBulkUpdateResponse{},
+
+		"ReservationRequest.json":        &csm.ReservationRequest{},
+		"ReservationRenewRequest.json":   &csm.ReservationRenewRequest{},
+		"ReservationReleaseRequest.json": &csm.ReservationReleaseRequest{},
+		"ReservationResponse.json":       &csm.ReservationResponse{},
+		"LockRequest.json":               &csm.LockRequest{},
+		"LockReleaseRequest.json":        &csm.LockReleaseRequest{},
+		"LockResponse.json":              &csm.LockResponse{},
+
+		"Group.json":      &csm.Group{},
+		"Partition.json":  &csm.Partition{},
+		"Membership.json": &csm.Membership{},
+
+		"Topology.json": &csm.Topology{},
+
+		"DiscoveryHistory.json":  &csm.DiscoveryHistory{},
+		"DiscoveryTemplate.json": &csm.DiscoveryTemplate{},
 	}
 
 	if err := os.MkdirAll(path, 0755); err != nil {
@@ -31,7 +52,7 @@ func generateAndWriteSchemas(path string) {
 	}
 
 	for filename, model := range schemas {
-		schema := jsonschema.Reflect(model)
+		schema := reflector.Reflect(model)
 		data, err := json.MarshalIndent(schema, "", "  ")
 		if err != nil {
 			log.Fatal("Failed to generate JSON schema")
@@ -45,5 +66,19 @@ func generateAndWriteSchemas(path string) {
 }
 
 func main() {
+	enums := flag.String("enums", "", "YAML or JSON file of site-specific Types, Roles and SubRoles to include in the schemas")
+	flag.Parse()
+
+	if *enums != "" {
+		if err := csm.LoadEnumExtensions(*enums); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if flag.Arg(0) == "inventory" {
+		if err := inventoryCommand(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	generateAndWriteSchemas("jsonschemas")
 }
@@ -0,0 +1,29 @@
+package schemas
+
+// ChassisDetail describes the chassis containing a node.  Parent is the
+// enclosure containing this chassis, if any, forming a chain up to the rack.
+type ChassisDetail struct {
+	URI          string         `json:"uri,omitempty"`          // URI of the Chassis
+	Type         string         `json:"type,omitempty"`         // Type of the Chassis, e.g. RackMount, Blade or Enclosure
+	SKU          string         `json:"sku,omitempty"`          // SKU of the Chassis
+	Serial       string         `json:"serial,omitempty"`       // Serial number of the Chassis
+	AssetTag     string         `json:"asset_tag,omitempty"`    // Asset tag of the Chassis
+	Manufacturer string         `json:"manufacturer,omitempty"` // Manufacturer of the Chassis
+	Model        string         `json:"model,omitempty"`        // Model of the Chassis
+	Parent       *ChassisDetail `json:"parent,omitempty"`       // Chassis containing this one
+}
+
+// Ancestors returns the chain of parents, nearest first.
+func (c ChassisDetail) Ancestors() []ChassisDetail {
+	var chain []ChassisDetail
+	for p := c.Parent; p != nil; p = p.Parent {
+		chain = append(chain, *p)
+	}
+	return chain
+}
+
+// IsZero reports whether no chassis information is set.
+func (c ChassisDetail) IsZero() bool {
+	return c.URI == "" && c.Type == "" && c.SKU == "" && c.Serial == "" && c.AssetTag == "" &&
+		c.Manufacturer == "" && c.Model == "" && c.Parent == nil
+}
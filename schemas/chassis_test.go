@@ -0,0 +1,29 @@
+package schemas
+
+import "testing"
+
+func TestChassisAncestors(t *testing.T) {
+	c := ChassisDetail{
+		Type:   "Blade",
+		Serial: "BLADE1",
+		Parent: &ChassisDetail{Type: "Enclosure", Serial: "ENC1", Parent: &ChassisDetail{Type: "Rack", Serial: "RACK1"}},
+	}
+	chain := c.Ancestors()
+	if len(chain) != 2 || chain[0].Serial != "ENC1" || chain[1].Serial != "RACK1" {
+		t.Errorf("Ancestors() = %+v, want the enclosure then the rack", chain)
+	}
+	if chain := (ChassisDetail{}).Ancestors(); chain != nil {
+		t.Errorf("Ancestors() of a chassis without a parent = %+v", chain)
+	}
+}
+
+func TestChassisIsZero(t *testing.T) {
+	if !(ChassisDetail{}).IsZero() {
+		t.Error("IsZero() of an empty chassis is false")
+	}
+	for _, c := range []ChassisDetail{{AssetTag: "A1"}, {Parent: &ChassisDetail{}}} {
+		if c.IsZero() {
+			t.Errorf("IsZero() of %+v is true", c)
+		}
+	}
+}
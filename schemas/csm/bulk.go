@@ -0,0 +1,271 @@
+package csm
+
+import (
+	"fmt"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+// ComponentSelector chooses the components a bulk operation applies to.  A
+// component is selected when it matches any of IDs, Parents or Ranges and also
+// matches Filter.  If only Filter is given, it is applied to every component.
+type ComponentSelector struct {
+	IDs     []string         `json:"IDs,omitempty" jsonschema:"description=Xnames of the components to select"`
+	Parents []string         `json:"Parents,omitempty" jsonschema:"description=Xnames whose descendants are selected along with the component itself e.g. x1000c3"`
+	Ranges  []string         `json:"Ranges,omitempty" jsonschema:"description=Bracketed xname ranges e.g. x1000c0s[0-7]b0n[0-1]"`
+	Filter  *ComponentFilter `json:"Filter,omitempty" jsonschema:"description=Restricts the selection to components matching every non-empty field"`
+}
+
+// ComponentFilter matches components by their attributes.  Each non-empty list
+// must contain the component's value for the component to match.
+type ComponentFilter struct {
+	Type    []ComponentType        `json:"Type,omitempty"`
+	Role    []ComponentRole        `json:"Role,omitempty"`
+	SubRole []ComponentSubRole     `json:"SubRole,omitempty"`
+	State   []ComponentState       `json:"State,omitempty"`
+	Flag    []ComponentFlag        `json:"Flag,omitempty"`
+	Class   []ComponentClass       `json:"Class,omitempty"`
+	Arch    []ComponentArch        `json:"Arch,omitempty"`
+	NetType []ComponentNetType     `json:"NetType,omitempty"`
+	Enabled schemas.Optional[bool] `json:"Enabled,omitempty,omitzero"`
+}
+
+// ComponentBulkFields holds the mutable fields of a bulk update.  Only the
+// fields that are set are applied.
+type ComponentBulkFields struct {
+	State          schemas.Optional[ComponentState]   `json:"State,omitempty,omitzero"`
+	Flag           schemas.Optional[ComponentFlag]    `json:"Flag,omitempty,omitzero"`
+	Enabled        schemas.Optional[bool]             `json:"Enabled,omitempty,omitzero"`
+	SoftwareStatus schemas.Optional[string]           `json:"SoftwareStatus,omitempty,omitzero"`
+	Role           schemas.Optional[ComponentRole]    `json:"Role,omitempty,omitzero"`
+	SubRole        schemas.Optional[ComponentSubRole] `json:"SubRole,omitempty,omitzero"`
+}
+
+// ComponentBulkUpdate applies the same field changes to many components, e.g.
+// "set State=Off, Flag=OK on these 400 xnames".
+type ComponentBulkUpdate struct {
+	Targets ComponentSelector   `json:"Targets" jsonschema:"description=The components to update"`
+	Fields  ComponentBulkFields `json:"Fields" jsonschema:"description=The field values to set on every selected component"`
+}
+
+type BulkResultStatus string
+
+const (
+	BulkUpdated   BulkResultStatus = "Updated"   // One or more fields were changed
+	BulkUnchanged BulkResultStatus = "Unchanged" // The component already had the requested values
+	BulkFailed    BulkResultStatus = "Failed"    // The component could not be updated
+	BulkNotFound  BulkResultStatus = "NotFound"  // An explicitly targeted xname does not exist
+)
+
+// ComponentBulkResult is the outcome of a bulk update for a single component.
+type ComponentBulkResult struct {
+	ID      string           `json:"ID" jsonschema:"description=Xname"`
+	Status  BulkResultStatus `json:"Status" jsonschema:"enum=Updated,enum=Unchanged,enum=Failed,enum=NotFound"`
+	Changed []string         `json:"Changed,omitempty" jsonschema:"description=Names of the fields that were changed"`
+	Error   string           `json:"Error,omitempty"`
+}
+
+// ComponentBulkUpdateResponse reports the per-component results of a bulk update.
+type ComponentBulkUpdateResponse struct {
+	Results []ComponentBulkResult `json:"Results"`
+	Updated int                   `json:"Updated"`
+	Failed  int                   `json:"Failed"`
+}
+
+// Validate checks that the update selects something, sets something, and that
+// every value is a known enum value.
+func (u ComponentBulkUpdate) Validate() error {
+	t := u.Targets
+	if len(t.IDs) == 0 && len(t.Parents) == 0 && len(t.Ranges) == 0 && t.Filter == nil {
+		return fmt.Errorf("bulk update does not select any components")
+	}
+	for _, id := range append(append([]string{}, t.IDs...), t.Parents...) {
+		if XnameSegments(id) == nil {
+			return fmt.Errorf("invalid xname %q", id)
+		}
+	}
+	for _, r := range t.Ranges {
+		if _, err := ExpandXnameRange(r); err != nil {
+			return err
+		}
+	}
+
+	f := u.Fields
+	if f == (ComponentBulkFields{}) {
+		return fmt.Errorf("bulk update does not set any fields")
+	}
+	if state, ok := f.State.Get(); ok {
+		if err := state.Valid(); err != nil {
+			return err
+		}
+	}
+	if flag, ok := f.Flag.Get(); ok {
+		if err := flag.Valid(); err != nil {
+			return err
+		}
+	}
+	if role, ok := f.Role.Get(); ok {
+		if err := role.Valid(); err != nil {
+			return err
+		}
+	}
+	if subRole, ok := f.SubRole.Get(); ok {
+		if err := subRole.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the component matches every non-empty field of the filter.
+func (f ComponentFilter) Matches(c Component) bool {
+	enabled, filtered := f.Enabled.Get()
+	return matchAny(f.Type, c.Type) &&
+		matchAny(f.Role, c.Role) &&
+		matchAny(f.SubRole, c.SubRole) &&
+		matchAny(f.State, c.State) &&
+		matchAny(f.Flag, c.Flag) &&
+		matchAny(f.Class, c.Class) &&
+		matchAny(f.Arch, c.Arch) &&
+		matchAny(f.NetType, c.NetType) &&
+		(!filtered || enabled == c.IsEnabled())
+}
+
+func matchAny[T comparable](values []T, v T) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the indices of the selected components, in slice order, and
+// any explicitly named xnames (from IDs or Ranges) that are not present.
+func (s ComponentSelector) Select(components []Component) ([]int, []string, error) {
+	named := make(map[string]bool)
+	var order []string
+	for _, id := range s.IDs {
+		if !named[id] {
+			named[id] = true
+			order = append(order, id)
+		}
+	}
+	for _, r := range s.Ranges {
+		xnames, err := ExpandXnameRange(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range xnames {
+			if !named[id] {
+				named[id] = true
+				order = append(order, id)
+			}
+		}
+	}
+
+	all := len(named) == 0 && len(s.Parents) == 0
+	found := make(map[string]bool)
+	var selected []int
+	for i, c := range components {
+		match := all || named[c.ID]
+		for _, parent := range s.Parents {
+			if match {
+				break
+			}
+			match = c.ID == parent || XnameIsAncestor(parent, c.ID)
+		}
+		if !match {
+			continue
+		}
+		found[c.ID] = true
+		if s.Filter != nil && !s.Filter.Matches(c) {
+			continue
+		}
+		selected = append(selected, i)
+	}
+
+	var missing []string
+	for _, id := range order {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return selected, missing, nil
+}
+
+// Apply validates the update and applies it in place to the selected
+// components.  Locked components are reported as failed and left untouched.
+func (u ComponentBulkUpdate) Apply(components []Component) (ComponentBulkUpdateResponse, error) {
+	var resp ComponentBulkUpdateResponse
+	if err := u.Validate(); err != nil {
+		return resp, err
+	}
+	selected, missing, err := u.Targets.Select(components)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, i := range selected {
+		c := &components[i]
+		result := ComponentBulkResult{ID: c.ID}
+		if c.Locked.OrElse(false) {
+			result.Status = BulkFailed
+			result.Error = "component is locked"
+			resp.Failed++
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+		result.Changed = u.Fields.apply(c)
+		if len(result.Changed) > 0 {
+			result.Status = BulkUpdated
+			resp.Updated++
+		} else {
+			result.Status = BulkUnchanged
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	for _, id := range missing {
+		resp.Results = append(resp.Results, ComponentBulkResult{
+			ID:     id,
+			Status: BulkNotFound,
+			Error:  "component not found",
+		})
+		resp.Failed++
+	}
+	return resp, nil
+}
+
+// apply sets the fields on the component and returns the names of the fields
+// whose values changed.
+func (f ComponentBulkFields) apply(c *Component) []string {
+	var changed []string
+	if state, ok := f.State.Get(); ok && c.State != state {
+		c.State = state
+		changed = append(changed, "State")
+	}
+	if flag, ok := f.Flag.Get(); ok && c.Flag != flag {
+		c.Flag = flag
+		changed = append(changed, "Flag")
+	}
+	if f.Enabled.IsSet() && c.Enabled != f.Enabled {
+		c.Enabled = f.Enabled
+		changed = append(changed, "Enabled")
+	}
+	if status, ok := f.SoftwareStatus.Get(); ok && c.SwStatus != status {
+		c.SwStatus = status
+		changed = append(changed, "SoftwareStatus")
+	}
+	if role, ok := f.Role.Get(); ok && c.Role != role {
+		c.Role = role
+		changed = append(changed, "Role")
+	}
+	if subRole, ok := f.SubRole.Get(); ok && c.SubRole != subRole {
+		c.SubRole = subRole
+		changed = append(changed, "SubRole")
+	}
+	return changed
+}
@@ -0,0 +1,114 @@
+package csm
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+func bulkComponents() []Component {
+	return []Component{
+		{ID: "x1000c0s0b0n0", Type: "Node", Role: RoleCompute, State: StateReady, Flag: FlagOK},
+		{ID: "x1000c0s0b0n1", Type: "Node", Role: RoleCompute, State: StateOff, Flag: FlagOK},
+		{ID: "x1000c0s1b0n0", Type: "Node", Role: RoleService, State: StateReady, Flag: FlagOK, Enabled: schemas.Some(false)},
+		{ID: "x1000c1s0b0n0", Type: "Node", Role: RoleCompute, State: StateReady, Flag: FlagOK},
+	}
+}
+
+func TestComponentSelectorSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector ComponentSelector
+		want     []int
+		missing  []string
+	}{
+		{"ids", ComponentSelector{IDs: []string{"x1000c0s0b0n1", "x1000c0s0b0n9"}}, []int{1}, []string{"x1000c0s0b0n9"}},
+		{"parent", ComponentSelector{Parents: []string{"x1000c0"}}, []int{0, 1, 2}, nil},
+		{"range", ComponentSelector{Ranges: []string{"x1000c0s0b0n[0-1]"}}, []int{0, 1}, nil},
+		{"filter only", ComponentSelector{Filter: &ComponentFilter{Role: []ComponentRole{RoleService}}}, []int{2}, nil},
+		{"parent and filter", ComponentSelector{Parents: []string{"x1000c0"}, Filter: &ComponentFilter{State: []ComponentState{StateReady}}}, []int{0, 2}, nil},
+		{"unset enabled counts as enabled", ComponentSelector{Filter: &ComponentFilter{Enabled: schemas.Some(true)}}, []int{0, 1, 3}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, missing, err := tt.selector.Select(bulkComponents())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(missing, tt.missing) {
+				t.Errorf("missing %v, want %v", missing, tt.missing)
+			}
+		})
+	}
+}
+
+func TestComponentBulkUpdateValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update ComponentBulkUpdate
+		ok     bool
+	}{
+		{"valid", ComponentBulkUpdate{Targets: ComponentSelector{IDs: []string{"x1000c0s0b0n0"}}, Fields: ComponentBulkFields{State: schemas.Some(StateOff)}}, true},
+		{"no targets", ComponentBulkUpdate{Fields: ComponentBulkFields{State: schemas.Some(StateOff)}}, false},
+		{"no fields", ComponentBulkUpdate{Targets: ComponentSelector{IDs: []string{"x1000c0s0b0n0"}}}, false},
+		{"bad xname", ComponentBulkUpdate{Targets: ComponentSelector{IDs: []string{"not an xname"}}, Fields: ComponentBulkFields{State: schemas.Some(StateOff)}}, false},
+		{"unknown state", ComponentBulkUpdate{Targets: ComponentSelector{IDs: []string{"x1000c0s0b0n0"}}, Fields: ComponentBulkFields{State: schemas.Some(ComponentState("Bogus"))}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.update.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestComponentBulkUpdateApply(t *testing.T) {
+	components := bulkComponents()
+	components[3].Locked = schemas.Some(true)
+	update := ComponentBulkUpdate{
+		Targets: ComponentSelector{Ranges: []string{"x1000c[0-1]s0b0n0"}, IDs: []string{"x1000c0s0b0n1", "x9000c0s0b0n0"}},
+		Fields:  ComponentBulkFields{State: schemas.Some(StateOff), Enabled: schemas.Some(false)},
+	}
+	resp, err := update.Apply(components)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]BulkResultStatus{
+		"x1000c0s0b0n0": BulkUpdated,
+		"x1000c0s0b0n1": BulkUpdated,
+		"x1000c1s0b0n0": BulkFailed,
+		"x9000c0s0b0n0": BulkNotFound,
+	}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(resp.Results), len(want), resp.Results)
+	}
+	for _, r := range resp.Results {
+		if r.Status != want[r.ID] {
+			t.Errorf("%s: status %s, want %s", r.ID, r.Status, want[r.ID])
+		}
+	}
+	if resp.Updated != 2 || resp.Failed != 2 {
+		t.Errorf("Updated %d Failed %d, want 2 and 2", resp.Updated, resp.Failed)
+	}
+	if got := resp.Results[1].Changed; !slices.Equal(got, []string{"Enabled"}) {
+		t.Errorf("x1000c0s0b0n1 changed %v, want [Enabled]", got)
+	}
+	if components[0].State != StateOff || components[0].IsEnabled() {
+		t.Errorf("x1000c0s0b0n0 = %+v, want Off and disabled", components[0])
+	}
+	if components[3].State != StateReady {
+		t.Errorf("locked component was changed")
+	}
+
+	// Applying again changes nothing.
+	resp, _ = update.Apply(components)
+	if resp.Updated != 0 || resp.Results[0].Status != BulkUnchanged {
+		t.Errorf("second Apply = %+v, want no updates", resp)
+	}
+}
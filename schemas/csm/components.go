@@ -3,26 +3,33 @@ package csm
 import (
 	"github.com/google/uuid"
 	"github.com/invopop/jsonschema"
+	"github.com/openchami/schemas/schemas"
 )
 
 // Component represents a CSM Component
 type Component struct {
-	UID                 uuid.UUID        `json:"UID,omitempty" db:"uid"`
-	ID                  string           `json:"ID" db:"id" jsonschema:"description=Xname"`
-	Type                ComponentType    `json:"Type" db:"type"`
-	Subtype             string           `json:"Subtype,omitempty" db:"subtype"`
-	Role                ComponentRole    `json:"Role,omitempty" db:"role"`
-	SubRole             ComponentSubRole `json:"SubRole,omitempty" db:"sub_role"`
-	NetType             ComponentNetType `json:"NetType,omitempty" db:"net_type"`
-	Arch                ComponentArch    `json:"Arch,omitempty" db:"arch"`
-	Class               ComponentClass   `json:"Class,omitempty" db:"class"`
-	State               ComponentState   `json:"State,omitempty" db:"state"`
-	Flag                ComponentFlag    `json:"Flag,omitempty" db:"flag"`
-	Enabled             bool             `json:"Enabled,omitempty" db:"enabled"`
-	SwStatus            string           `json:"SoftwareStatus,omitempty" db:"sw_status"`
-	NID                 int              `json:"NID,omitempty" db:"nid"`
-	ReservationDisabled bool             `json:"ReservationDisabled,omitempty" db:"reservation_disabled"`
-	Locked              bool             `json:"Locked,omitempty" db:"locked"`
+	UID                 uuid.UUID              `json:"UID,omitempty" db:"uid"`
+	ID                  string                 `json:"ID" db:"id" jsonschema:"description=Xname"`
+	Type                ComponentType          `json:"Type" db:"type"`
+	Subtype             string                 `json:"Subtype,omitempty" db:"subtype"`
+	Role                ComponentRole          `json:"Role,omitempty" db:"role"`
+	SubRole             ComponentSubRole       `json:"SubRole,omitempty" db:"sub_role"`
+	NetType             ComponentNetType       `json:"NetType,omitempty" db:"net_type"`
+	Arch                ComponentArch          `json:"Arch,omitempty" db:"arch"`
+	Class               ComponentClass         `json:"Class,omitempty" db:"class"`
+	State               ComponentState         `json:"State,omitempty" db:"state"`
+	Flag                ComponentFlag          `json:"Flag,omitempty" db:"flag"`
+	Enabled             schemas.Optional[bool] `json:"Enabled,omitempty,omitzero" db:"enabled"`
+	SwStatus            string                 `json:"SoftwareStatus,omitempty" db:"sw_status"`
+	NID                 schemas.Optional[int]  `json:"NID,omitempty,omitzero" db:"nid"`
+	ReservationDisabled schemas.Optional[bool] `json:"ReservationDisabled,omitempty,omitzero" db:"reservation_disabled"`
+	Locked              schemas.Optional[bool] `json:"Locked,omitempty,omitzero" db:"locked"`
+}
+
+// IsEnabled reports whether the component is enabled.  An unset Enabled
+// counts as enabled, as components are enabled when they are created.
+func (c Component) IsEnabled() bool {
+	return c.Enabled.OrElse(true)
 }
 
 type ComponentType string
@@ -67,52 +74,59 @@ const (
 	TypeINVALID                  ComponentType = "INVALID"
 )
 
+var componentTypes = newEnumRegistry(
+	string(TypeCDU),
+	string(TypeCabinetCDU),
+	string(TypeCabinetPDU),
+	string(TypeCabinetPDUOutlet),
+	string(TypeCabinetPDUPowerConnector),
+	string(TypeCabinetPDUController),
+	string(TypeCabinet),
+	string(TypeChassis),
+	string(TypeChassisBMC),
+	string(TypeCMMRectifier),
+	string(TypeCMMFpga),
+	string(TypeCEC),
+	string(TypeComputeModule),
+	string(TypeRouterModule),
+	string(TypeNodeBMC),
+	string(TypeNodeEnclosure),
+	string(TypeNodeEnclosurePowerSupply),
+	string(TypeHSNBoard),
+	string(TypeMgmtSwitch),
+	string(TypeMgmtHLSwitch),
+	string(TypeCDUMgmtSwitch),
+	string(TypeNode),
+	string(TypeVirtualNode),
+	string(TypeProcessor),
+	string(TypeDrive),
+	string(TypeStorageGroup),
+	string(TypeNodeNIC),
+	string(TypeMemory),
+	string(TypeNodeAccel),
+	string(TypeNodeAccelRiser),
+	string(TypeNodeFpga),
+	string(TypeHSNAsic),
+	string(TypeRouterFpga),
+	string(TypeRouterBMC),
+	string(TypeHSNLink),
+	string(TypeHSNConnector),
+	string(TypeINVALID),
+)
+
 func (ComponentType) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(TypeCDU),
-			string(TypeCabinetCDU),
-			string(TypeCabinetPDU),
-			string(TypeCabinetPDUOutlet),
-			string(TypeCabinetPDUPowerConnector),
-			string(TypeCabinetPDUController),
-			string(TypeCabinet),
-			string(TypeChassis),
-			string(TypeChassisBMC),
-			string(TypeCMMRectifier),
-			string(TypeCMMFpga),
-			string(TypeCEC),
-			string(TypeComputeModule),
-			string(TypeRouterModule),
-			string(TypeNodeBMC),
-			string(TypeNodeEnclosure),
-			string(TypeNodeEnclosurePowerSupply),
-			string(TypeHSNBoard),
-			string(TypeMgmtSwitch),
-			string(TypeMgmtHLSwitch),
-			string(TypeCDUMgmtSwitch),
-			string(TypeNode),
-			string(TypeVirtualNode),
-			string(TypeProcessor),
-			string(TypeDrive),
-			string(TypeStorageGroup),
-			string(TypeNodeNIC),
-			string(TypeMemory),
-			string(TypeNodeAccel),
-			string(TypeNodeAccelRiser),
-			string(TypeNodeFpga),
-			string(TypeHSNAsic),
-			string(TypeRouterFpga),
-			string(TypeRouterBMC),
-			string(TypeHSNLink),
-			string(TypeHSNConnector),
-			string(TypeINVALID),
-		},
+		Type:        "string",
+		Enum:        componentTypes.enum(),
 		Description: "This is the CSM component type category.  It has a particular xname format and represents the kind of component that can occupy that location.  Not to be confused with RedfishType which is Redfish specific and only used when providing Redfish endpoint data from discovery.",
 	}
 }
 
+// Valid returns an error unless the component type is one of the known values.
+func (t ComponentType) Valid() error {
+	return componentTypes.validate("component type", string(t))
+}
+
 // ComponentState represents the state of an CSM component
 type ComponentState string
 
@@ -128,23 +142,30 @@ const (
 	StateReady   ComponentState = "Ready"   // Both On and Ready to provide its expected services, i.e. used for jobs.
 )
 
+var componentStates = newEnumRegistry(
+	string(StateUnknown),
+	string(StateEmpty),
+	string(StatePopulated),
+	string(StateOff),
+	string(StateOn),
+	string(StateStandby),
+	string(StateHalt),
+	string(StateReady),
+)
+
 func (ComponentState) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(StateUnknown),
-			string(StateEmpty),
-			string(StatePopulated),
-			string(StateOff),
-			string(StateOn),
-			string(StateStandby),
-			string(StateHalt),
-			string(StateReady),
-		},
+		Type:        "string",
+		Enum:        componentStates.enum(),
 		Description: "The state of an CSM component",
 	}
 }
 
+// Valid returns an error unless the state is one of the known values.
+func (s ComponentState) Valid() error {
+	return componentStates.validate("state", string(s))
+}
+
 type ComponentFlag string
 
 // Valid flag values.
@@ -156,20 +177,27 @@ const (
 	FlagLocked  ComponentFlag = "Locked"  // Another service has reserved this component.
 )
 
+var componentFlags = newEnumRegistry(
+	string(FlagUnknown),
+	string(FlagOK),
+	string(FlagWarning),
+	string(FlagAlert),
+	string(FlagLocked),
+)
+
 func (ComponentFlag) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(FlagUnknown),
-			string(FlagOK),
-			string(FlagWarning),
-			string(FlagAlert),
-			string(FlagLocked),
-		},
+		Type:        "string",
+		Enum:        componentFlags.enum(),
 		Description: "The flag of an CSM component",
 	}
 }
 
+// Valid returns an error unless the flag is one of the known values.
+func (f ComponentFlag) Valid() error {
+	return componentFlags.validate("flag", string(f))
+}
+
 type ComponentRole string
 
 // Valid role values.
@@ -182,21 +210,28 @@ const (
 	RoleManagement  ComponentRole = "Management"
 )
 
+var componentRoles = newEnumRegistry(
+	string(RoleCompute),
+	string(RoleService),
+	string(RoleSystem),
+	string(RoleApplication),
+	string(RoleStorage),
+	string(RoleManagement),
+)
+
 func (ComponentRole) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(RoleCompute),
-			string(RoleService),
-			string(RoleSystem),
-			string(RoleApplication),
-			string(RoleStorage),
-			string(RoleManagement),
-		},
+		Type:        "string",
+		Enum:        componentRoles.enum(),
 		Description: "The role of an CSM component",
 	}
 }
 
+// Valid returns an error unless the role is one of the known values.
+func (r ComponentRole) Valid() error {
+	return componentRoles.validate("role", string(r))
+}
+
 type ComponentSubRole string
 
 // Valid SubRole values.
@@ -206,18 +241,25 @@ const (
 	SubRoleStorage ComponentSubRole = "Storage"
 )
 
+var componentSubRoles = newEnumRegistry(
+	string(SubRoleMaster),
+	string(SubRoleWorker),
+	string(SubRoleStorage),
+)
+
 func (ComponentSubRole) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(SubRoleMaster),
-			string(SubRoleWorker),
-			string(SubRoleStorage),
-		},
+		Type:        "string",
+		Enum:        componentSubRoles.enum(),
 		Description: "The sub-role of an CSM component",
 	}
 }
 
+// Valid returns an error unless the sub-role is one of the known values.
+func (r ComponentSubRole) Valid() error {
+	return componentSubRoles.validate("sub-role", string(r))
+}
+
 type ComponentNetType string
 
 const (
@@ -228,20 +270,27 @@ const (
 	NetNone       ComponentNetType = "None"
 )
 
+var componentNetTypes = newEnumRegistry(
+	string(NetSling),
+	string(NetInfiniband),
+	string(NetEthernet),
+	string(NetOEM),
+	string(NetNone),
+)
+
 func (ComponentNetType) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(NetSling),
-			string(NetInfiniband),
-			string(NetEthernet),
-			string(NetOEM),
-			string(NetNone),
-		},
+		Type:        "string",
+		Enum:        componentNetTypes.enum(),
 		Description: "The network type of an CSM component",
 	}
 }
 
+// Valid returns an error unless the network type is one of the known values.
+func (n ComponentNetType) Valid() error {
+	return componentNetTypes.validate("network type", string(n))
+}
+
 type ComponentArch string
 
 const (
@@ -251,19 +300,26 @@ const (
 	ArchOther   ComponentArch = "Other"
 )
 
+var componentArchs = newEnumRegistry(
+	string(ArchX86),
+	string(ArchARM),
+	string(ArchUnknown),
+	string(ArchOther),
+)
+
 func (ComponentArch) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(ArchX86),
-			string(ArchARM),
-			string(ArchUnknown),
-			string(ArchOther),
-		},
+		Type:        "string",
+		Enum:        componentArchs.enum(),
 		Description: "The architecture of an CSM component",
 	}
 }
 
+// Valid returns an error unless the architecture is one of the known values.
+func (a ComponentArch) Valid() error {
+	return componentArchs.validate("architecture", string(a))
+}
+
 type ComponentClass string
 
 const (
@@ -273,15 +329,22 @@ const (
 	ClassOther    ComponentClass = "Other"
 )
 
+var componentClasses = newEnumRegistry(
+	string(ClassRiver),
+	string(ClassMountain),
+	string(ClassHill),
+	string(ClassOther),
+)
+
 func (ComponentClass) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
-		Type: "string",
-		Enum: []interface{}{
-			string(ClassRiver),
-			string(ClassMountain),
-			string(ClassHill),
-			string(ClassOther),
-		},
+		Type:        "string",
+		Enum:        componentClasses.enum(),
 		Description: "The class of an CSM component",
 	}
 }
+
+// Valid returns an error unless the class is one of the known values.
+func (c ComponentClass) Valid() error {
+	return componentClasses.validate("class", string(c))
+}
@@ -0,0 +1,85 @@
+package csm
+
+import (
+	"encoding/json"
+
+	"github.com/invopop/jsonschema"
+)
+
+// DiscoveryStatus describes the outcome of a Redfish discovery attempt.
+type DiscoveryStatus string
+
+const (
+	DiscoveryEndpointInvalid         DiscoveryStatus = "EndpointInvalid"         // The endpoint is not a usable Redfish service, e.g. bad hostname or service root
+	DiscoveryEPResponseFailedDecode  DiscoveryStatus = "EPResponseFailedDecode"  // The endpoint responded, but the response could not be decoded
+	DiscoveryHTTPsGetFailed          DiscoveryStatus = "HTTPsGetFailed"          // The HTTPS request to the endpoint failed
+	DiscoveryNotYetQueried           DiscoveryStatus = "NotYetQueried"           // Discovery has not been attempted yet
+	DiscoveryVerificationFailed      DiscoveryStatus = "VerificationFailed"      // The endpoint could not be verified, e.g. bad credentials
+	DiscoveryChildVerificationFailed DiscoveryStatus = "ChildVerificationFailed" // One or more resources beneath the service root could not be verified
+	DiscoveryOK                      DiscoveryStatus = "DiscoverOK"              // Discovery completed successfully
+)
+
+var discoveryStatuses = newEnumRegistry(
+	string(DiscoveryEndpointInvalid),
+	string(DiscoveryEPResponseFailedDecode),
+	string(DiscoveryHTTPsGetFailed),
+	string(DiscoveryNotYetQueried),
+	string(DiscoveryVerificationFailed),
+	string(DiscoveryChildVerificationFailed),
+	string(DiscoveryOK),
+)
+
+func (DiscoveryStatus) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        discoveryStatuses.enum(),
+		Description: "The outcome of a Redfish discovery attempt",
+	}
+}
+
+// Valid returns an error unless the status is one of the known values.
+func (s DiscoveryStatus) Valid() error {
+	return discoveryStatuses.validate("discovery status", string(s))
+}
+
+// UnmarshalJSON rejects unknown statuses.  An empty string is accepted as unset.
+func (s *DiscoveryStatus) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	status := DiscoveryStatus(value)
+	if status != "" {
+		if err := status.Valid(); err != nil {
+			return err
+		}
+	}
+	*s = status
+	return nil
+}
+
+// IsSuccess reports whether discovery completed successfully.
+func (s DiscoveryStatus) IsSuccess() bool {
+	return s == DiscoveryOK
+}
+
+// IsRetryable reports whether the failure is likely transient, so that
+// retrying without changing the endpoint may succeed.
+func (s DiscoveryStatus) IsRetryable() bool {
+	switch s {
+	case DiscoveryHTTPsGetFailed, DiscoveryEPResponseFailedDecode:
+		return true
+	}
+	return false
+}
+
+// IsTerminal reports whether the outcome will not change by retrying: either
+// discovery succeeded, or it failed in a way that needs the endpoint or its
+// credentials to be fixed first.
+func (s DiscoveryStatus) IsTerminal() bool {
+	switch s {
+	case DiscoveryOK, DiscoveryEndpointInvalid, DiscoveryVerificationFailed, DiscoveryChildVerificationFailed:
+		return true
+	}
+	return false
+}
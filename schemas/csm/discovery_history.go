@@ -0,0 +1,142 @@
+package csm
+
+import "time"
+
+// DefaultDiscoveryHistorySize is the number of attempts kept when a
+// DiscoveryHistory does not specify a size.
+const DefaultDiscoveryHistorySize = 16
+
+// DiscoveryAttempt records a single discovery attempt against an endpoint.
+type DiscoveryAttempt struct {
+	Attempted      time.Time       `json:"Attempted" jsonschema:"description=Time the discovery was started,format=date-time"`
+	Completed      time.Time       `json:"Completed,omitempty" jsonschema:"description=Time the discovery was completed,format=date-time"`
+	Status         DiscoveryStatus `json:"Status" jsonschema:"description=Describes the outcome of the discovery attempt"`
+	RedfishVersion string          `json:"RedfishVersion,omitempty" jsonschema:"description=Version of Redfish as reported by the RF service root"`
+	Error          string          `json:"Error,omitempty" jsonschema:"description=Detail of why the attempt failed"`
+}
+
+// Duration returns how long the attempt took, or zero if it has not completed.
+func (a DiscoveryAttempt) Duration() time.Duration {
+	if a.Completed.IsZero() || a.Completed.Before(a.Attempted) {
+		return 0
+	}
+	return a.Completed.Sub(a.Attempted)
+}
+
+// DiscoveryHistory keeps the most recent discovery attempts for an endpoint so
+// that flapping endpoints can be recognized.  Once Size attempts are held, the
+// oldest attempt is dropped for each new one recorded.
+type DiscoveryHistory struct {
+	EndpointID string             `json:"EndpointID" jsonschema:"description=ID of the Redfish endpoint"`
+	Size       int                `json:"Size,omitempty" jsonschema:"description=Maximum number of attempts kept. Defaults to 16.,minimum=1"`
+	Attempts   []DiscoveryAttempt `json:"Attempts" jsonschema:"description=Discovery attempts ordered from oldest to newest"`
+}
+
+// NewDiscoveryHistory creates an empty history for an endpoint.  A size of
+// zero uses DefaultDiscoveryHistorySize.
+func NewDiscoveryHistory(endpointID string, size int) *DiscoveryHistory {
+	return &DiscoveryHistory{EndpointID: endpointID, Size: size}
+}
+
+func (h *DiscoveryHistory) size() int {
+	if h.Size < 1 {
+		return DefaultDiscoveryHistorySize
+	}
+	return h.Size
+}
+
+// Record adds an attempt to the history, keeping the attempts ordered by their
+// start time and dropping the oldest beyond the history size.
+func (h *DiscoveryHistory) Record(a DiscoveryAttempt) {
+	i := len(h.Attempts)
+	for i > 0 && h.Attempts[i-1].Attempted.After(a.Attempted) {
+		i--
+	}
+	h.Attempts = append(h.Attempts, DiscoveryAttempt{})
+	copy(h.Attempts[i+1:], h.Attempts[i:])
+	h.Attempts[i] = a
+
+	if extra := len(h.Attempts) - h.size(); extra > 0 {
+		h.Attempts = append([]DiscoveryAttempt(nil), h.Attempts[extra:]...)
+	}
+}
+
+// RecordDiscovery records the outcome of a RedfishDiscovery, with an optional
+// error detail for failed attempts.
+func (h *DiscoveryHistory) RecordDiscovery(d RedfishDiscovery, detail string) {
+	h.Record(DiscoveryAttempt{
+		Attempted:      d.Attempted,
+		Completed:      d.Completed,
+		Status:         d.Status,
+		RedfishVersion: d.Payload.DiscoveryInfo.RedfishVersion,
+		Error:          detail,
+	})
+}
+
+// Latest returns the most recent attempt.
+func (h DiscoveryHistory) Latest() (DiscoveryAttempt, bool) {
+	if len(h.Attempts) == 0 {
+		return DiscoveryAttempt{}, false
+	}
+	return h.Attempts[len(h.Attempts)-1], true
+}
+
+// LastSuccess returns the most recent successful attempt still in the history.
+func (h DiscoveryHistory) LastSuccess() (DiscoveryAttempt, bool) {
+	for i := len(h.Attempts) - 1; i >= 0; i-- {
+		if h.Attempts[i].Status.IsSuccess() {
+			return h.Attempts[i], true
+		}
+	}
+	return DiscoveryAttempt{}, false
+}
+
+// FailureStreak returns the number of consecutive failed attempts since the
+// last success.  Attempts that are NotYetQueried are not counted.
+func (h DiscoveryHistory) FailureStreak() int {
+	streak := 0
+	for i := len(h.Attempts) - 1; i >= 0; i-- {
+		status := h.Attempts[i].Status
+		if status.IsSuccess() {
+			break
+		}
+		if status != DiscoveryNotYetQueried && status != "" {
+			streak++
+		}
+	}
+	return streak
+}
+
+// TimeSinceLastSuccess returns how long ago the last successful attempt
+// completed.  It returns false if no success is in the history.
+func (h DiscoveryHistory) TimeSinceLastSuccess(now time.Time) (time.Duration, bool) {
+	a, ok := h.LastSuccess()
+	if !ok {
+		return 0, false
+	}
+	at := a.Completed
+	if at.IsZero() {
+		at = a.Attempted
+	}
+	return now.Sub(at), true
+}
+
+// DiscoveryInfo derives the current DiscoveryInfo of the endpoint from its
+// latest attempt and the most recent Redfish version reported.
+func (h DiscoveryHistory) DiscoveryInfo() DiscoveryInfo {
+	latest, ok := h.Latest()
+	if !ok {
+		return DiscoveryInfo{LastStatus: DiscoveryNotYetQueried}
+	}
+	info := DiscoveryInfo{
+		LastAttempt: latest.Attempted,
+		LastStatus:  latest.Status,
+	}
+	for i := len(h.Attempts) - 1; i >= 0; i-- {
+		if h.Attempts[i].RedfishVersion != "" {
+			info.RedfishVersion = h.Attempts[i].RedfishVersion
+			break
+		}
+	}
+	return info
+}
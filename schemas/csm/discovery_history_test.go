@@ -0,0 +1,74 @@
+package csm
+
+import (
+	"testing"
+	"time"
+)
+
+var historyStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func attempt(minute int, status DiscoveryStatus) DiscoveryAttempt {
+	at := historyStart.Add(time.Duration(minute) * time.Minute)
+	return DiscoveryAttempt{Attempted: at, Completed: at.Add(time.Second), Status: status}
+}
+
+func TestDiscoveryHistoryRecord(t *testing.T) {
+	h := NewDiscoveryHistory("x1000c0s0b0", 3)
+	for _, minute := range []int{1, 3, 0, 2} {
+		h.Record(attempt(minute, DiscoveryOK))
+	}
+	if len(h.Attempts) != 3 {
+		t.Fatalf("kept %d attempts, want 3", len(h.Attempts))
+	}
+	for i, minute := range []int{1, 2, 3} {
+		if want := historyStart.Add(time.Duration(minute) * time.Minute); !h.Attempts[i].Attempted.Equal(want) {
+			t.Errorf("attempt %d started %v, want %v", i, h.Attempts[i].Attempted, want)
+		}
+	}
+	if d := h.Attempts[0].Duration(); d != time.Second {
+		t.Errorf("Duration() = %v, want 1s", d)
+	}
+
+	unbounded := NewDiscoveryHistory("x1000c0s0b0", 0)
+	for i := 0; i < DefaultDiscoveryHistorySize+5; i++ {
+		unbounded.Record(attempt(i, DiscoveryOK))
+	}
+	if len(unbounded.Attempts) != DefaultDiscoveryHistorySize {
+		t.Errorf("kept %d attempts, want %d", len(unbounded.Attempts), DefaultDiscoveryHistorySize)
+	}
+}
+
+func TestDiscoveryHistoryFailures(t *testing.T) {
+	h := NewDiscoveryHistory("x1000c0s0b0", 0)
+	if _, ok := h.LastSuccess(); ok {
+		t.Error("LastSuccess() of an empty history succeeded")
+	}
+	if info := h.DiscoveryInfo(); info.LastStatus != DiscoveryNotYetQueried {
+		t.Errorf("DiscoveryInfo() of an empty history = %+v", info)
+	}
+
+	h.Record(DiscoveryAttempt{Attempted: historyStart, Completed: historyStart.Add(time.Second), Status: DiscoveryOK, RedfishVersion: "1.6.0"})
+	h.Record(attempt(1, DiscoveryHTTPsGetFailed))
+	h.Record(attempt(2, DiscoveryNotYetQueried))
+	h.Record(attempt(3, DiscoveryVerificationFailed))
+
+	if got := h.FailureStreak(); got != 2 {
+		t.Errorf("FailureStreak() = %d, want 2", got)
+	}
+	since, ok := h.TimeSinceLastSuccess(historyStart.Add(10 * time.Minute))
+	if !ok || since != 10*time.Minute-time.Second {
+		t.Errorf("TimeSinceLastSuccess() = %v, %v", since, ok)
+	}
+	info := h.DiscoveryInfo()
+	if info.LastStatus != DiscoveryVerificationFailed || info.RedfishVersion != "1.6.0" || !info.LastAttempt.Equal(historyStart.Add(3*time.Minute)) {
+		t.Errorf("DiscoveryInfo() = %+v", info)
+	}
+
+	h.RecordDiscovery(RedfishDiscovery{Attempted: historyStart.Add(4 * time.Minute), Status: DiscoveryOK, Payload: RedfishEndpoint{DiscoveryInfo: DiscoveryInfo{RedfishVersion: "1.8.0"}}}, "")
+	if got := h.FailureStreak(); got != 0 {
+		t.Errorf("FailureStreak() after a success = %d, want 0", got)
+	}
+	if info := h.DiscoveryInfo(); info.RedfishVersion != "1.8.0" {
+		t.Errorf("DiscoveryInfo().RedfishVersion = %s, want 1.8.0", info.RedfishVersion)
+	}
+}
@@ -0,0 +1,267 @@
+package csm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/invopop/jsonschema"
+	"github.com/openchami/schemas/schemas"
+)
+
+// Defaults applied when a template leaves a setting unset.
+const (
+	DefaultDiscoveryTimeoutSeconds = 30
+	DefaultDiscoveryMaxAttempts    = 3
+	DefaultDiscoveryBackoffSeconds = 5
+)
+
+// RedfishCollection names a Redfish collection that discovery walks.
+type RedfishCollection string
+
+const (
+	CollectionSystems            RedfishCollection = "Systems"
+	CollectionChassis            RedfishCollection = "Chassis"
+	CollectionManagers           RedfishCollection = "Managers"
+	CollectionEthernetInterfaces RedfishCollection = "EthernetInterfaces"
+	CollectionNetworkInterfaces  RedfishCollection = "NetworkInterfaces"
+	CollectionNetworkAdapters    RedfishCollection = "NetworkAdapters"
+	CollectionProcessors         RedfishCollection = "Processors"
+	CollectionMemory             RedfishCollection = "Memory"
+	CollectionStorage            RedfishCollection = "Storage"
+	CollectionPCIeDevices        RedfishCollection = "PCIeDevices"
+)
+
+var redfishCollections = newEnumRegistry(
+	string(CollectionSystems),
+	string(CollectionChassis),
+	string(CollectionManagers),
+	string(CollectionEthernetInterfaces),
+	string(CollectionNetworkInterfaces),
+	string(CollectionNetworkAdapters),
+	string(CollectionProcessors),
+	string(CollectionMemory),
+	string(CollectionStorage),
+	string(CollectionPCIeDevices),
+)
+
+// DefaultRedfishCollections are walked when a template does not list any.
+var DefaultRedfishCollections = []RedfishCollection{
+	CollectionSystems,
+	CollectionChassis,
+	CollectionManagers,
+	CollectionEthernetInterfaces,
+	CollectionNetworkInterfaces,
+	CollectionNetworkAdapters,
+	CollectionProcessors,
+	CollectionMemory,
+	CollectionStorage,
+	CollectionPCIeDevices,
+}
+
+func (RedfishCollection) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        redfishCollections.enum(),
+		Description: "A Redfish collection walked during discovery",
+	}
+}
+
+// Valid returns an error unless the collection is one of the known values.
+func (c RedfishCollection) Valid() error {
+	return redfishCollections.validate("Redfish collection", string(c))
+}
+
+// TLSPolicy controls how the endpoint's certificate is checked.
+type TLSPolicy struct {
+	InsecureSkipVerify bool   `json:"InsecureSkipVerify,omitempty" jsonschema:"description=Accept any certificate presented by the endpoint. Common for BMCs with self-signed certificates."`
+	MinVersion         string `json:"MinVersion,omitempty" jsonschema:"description=Minimum TLS version to negotiate,enum=1.0,enum=1.1,enum=1.2,enum=1.3"`
+	CABundle           string `json:"CABundle,omitempty" jsonschema:"description=Path to a PEM file of CA certificates used to verify the endpoint"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (p TLSPolicy) Valid() error {
+	if _, ok := tlsVersions[p.MinVersion]; p.MinVersion != "" && !ok {
+		return fmt.Errorf("unknown TLS version %q", p.MinVersion)
+	}
+	return nil
+}
+
+// Config builds a tls.Config implementing the policy.
+func (p TLSPolicy) Config() (*tls.Config, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: p.InsecureSkipVerify,
+		MinVersion:         tlsVersions[p.MinVersion],
+	}
+	if p.CABundle != "" {
+		pem, err := os.ReadFile(p.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", p.CABundle)
+		}
+	}
+	return config, nil
+}
+
+// RetryPolicy controls how failed discovery attempts are retried.  The delay
+// doubles after each attempt, up to MaxBackoffSeconds.
+type RetryPolicy struct {
+	MaxAttempts       int                   `json:"MaxAttempts,omitempty" jsonschema:"description=Total number of attempts including the first,minimum=1,default=3"`
+	BackoffSeconds    schemas.Optional[int] `json:"BackoffSeconds,omitempty,omitzero" jsonschema:"description=Delay before the first retry. Zero retries immediately.,minimum=0,default=5"`
+	MaxBackoffSeconds int                   `json:"MaxBackoffSeconds,omitempty" jsonschema:"description=Upper bound on the delay between retries. Zero means no bound.,minimum=0"`
+}
+
+// Backoff returns the delay before the given retry, counting from 1.  An
+// unset BackoffSeconds uses DefaultDiscoveryBackoffSeconds.  Without a
+// MaxBackoffSeconds the delay stops doubling before it would overflow.
+func (r RetryPolicy) Backoff(retry int) time.Duration {
+	delay := time.Duration(r.BackoffSeconds.OrElse(DefaultDiscoveryBackoffSeconds)) * time.Second
+	limit := time.Duration(r.MaxBackoffSeconds) * time.Second
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	for i := 1; i < retry && delay > 0 && delay < limit; i++ {
+		if delay > limit/2 {
+			return limit
+		}
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// DiscoveryTemplate defines how a class of endpoints should be discovered.
+// Endpoints refer to a template with RedfishEndpoint.TemplateID.
+type DiscoveryTemplate struct {
+	ID             string                 `json:"ID" jsonschema:"description=Unique ID referenced by RedfishEndpoint.TemplateID"`
+	Name           string                 `json:"Name,omitempty"`
+	Description    string                 `json:"Description,omitempty"`
+	User           string                 `json:"User,omitempty" jsonschema:"description=Default username for endpoints that do not set one"`
+	CredentialRef  *CredentialRef         `json:"CredentialRef,omitempty" jsonschema:"description=Default credentials for endpoints that do not provide their own"`
+	TLS            TLSPolicy              `json:"TLS,omitempty"`
+	Collections    []RedfishCollection    `json:"Collections,omitempty" jsonschema:"description=Redfish collections to walk. Defaults to all known collections."`
+	TimeoutSeconds schemas.Optional[int]  `json:"TimeoutSeconds,omitempty,omitzero" jsonschema:"description=Timeout for each Redfish request. Zero means no timeout.,minimum=0,default=30"`
+	Retry          RetryPolicy            `json:"Retry,omitempty"`
+	UseSSDP        schemas.Optional[bool] `json:"UseSSDP,omitempty,omitzero" jsonschema:"description=Whether to use SSDP for discovery if the EP supports it."`
+	MacRequired    schemas.Optional[bool] `json:"MacRequired,omitempty,omitzero" jsonschema:"description=Whether the MAC must be used in setting up geolocation info."`
+}
+
+func (t DiscoveryTemplate) Valid() error {
+	if t.ID == "" {
+		return fmt.Errorf("discovery template has no ID")
+	}
+	if t.CredentialRef != nil {
+		if err := t.CredentialRef.Valid(); err != nil {
+			return fmt.Errorf("template %s: %w", t.ID, err)
+		}
+	}
+	if err := t.TLS.Valid(); err != nil {
+		return fmt.Errorf("template %s: %w", t.ID, err)
+	}
+	for _, c := range t.Collections {
+		if err := c.Valid(); err != nil {
+			return fmt.Errorf("template %s: %w", t.ID, err)
+		}
+	}
+	if t.TimeoutSeconds.OrElse(0) < 0 {
+		return fmt.Errorf("template %s: timeout cannot be negative", t.ID)
+	}
+	if t.Retry.MaxAttempts < 0 || t.Retry.BackoffSeconds.OrElse(0) < 0 || t.Retry.MaxBackoffSeconds < 0 {
+		return fmt.Errorf("template %s: retry settings cannot be negative", t.ID)
+	}
+	return nil
+}
+
+// ResolvedDiscovery is the effective configuration for discovering a single
+// endpoint after applying its template.
+type ResolvedDiscovery struct {
+	Endpoint    RedfishEndpoint // The endpoint with its credentials and flags resolved
+	TLS         TLSPolicy
+	Collections []RedfishCollection
+	Timeout     time.Duration
+	Retry       RetryPolicy
+}
+
+// ResolveDiscoveryTemplate merges a template with the per-endpoint settings
+// of ep.  Credentials, UseSSDP and MacRequired set on the endpoint take
+// precedence over the template's, and the resolved endpoint always has
+// UseSSDP and MacRequired set.  Unset settings receive the package defaults.
+func ResolveDiscoveryTemplate(t DiscoveryTemplate, ep RedfishEndpoint) (ResolvedDiscovery, error) {
+	if ep.TemplateID != "" && ep.TemplateID != t.ID {
+		return ResolvedDiscovery{}, fmt.Errorf("endpoint %s uses template %s, not %s", ep.ID, ep.TemplateID, t.ID)
+	}
+	if t.ID != "" {
+		if err := t.Valid(); err != nil {
+			return ResolvedDiscovery{}, err
+		}
+	}
+	if err := ep.ValidCredentials(); err != nil {
+		return ResolvedDiscovery{}, err
+	}
+
+	r := ResolvedDiscovery{
+		Endpoint:    ep,
+		TLS:         t.TLS,
+		Collections: t.Collections,
+		Timeout:     time.Duration(t.TimeoutSeconds.OrElse(DefaultDiscoveryTimeoutSeconds)) * time.Second,
+		Retry:       t.Retry,
+	}
+	if r.Endpoint.User == "" {
+		r.Endpoint.User = t.User
+	}
+	if r.Endpoint.Password == "" && r.Endpoint.CredentialRef == nil && t.CredentialRef != nil {
+		ref := *t.CredentialRef
+		r.Endpoint.CredentialRef = &ref
+	}
+	r.Endpoint.UseSSDP = schemas.Some(ep.UseSSDP.OrElse(t.UseSSDP.OrElse(false)))
+	r.Endpoint.MacRequired = schemas.Some(ep.MacRequired.OrElse(t.MacRequired.OrElse(false)))
+
+	if len(r.Collections) == 0 {
+		r.Collections = append([]RedfishCollection(nil), DefaultRedfishCollections...)
+	}
+	if r.Retry.MaxAttempts == 0 {
+		r.Retry.MaxAttempts = DefaultDiscoveryMaxAttempts
+	}
+	if !r.Retry.BackoffSeconds.IsSet() {
+		r.Retry.BackoffSeconds = schemas.Some(DefaultDiscoveryBackoffSeconds)
+	}
+	return r, nil
+}
+
+// ResolveDiscovery finds the template named by ep.TemplateID and resolves it.
+// Endpoints without a TemplateID are resolved against the defaults.
+func ResolveDiscovery(templates []DiscoveryTemplate, ep RedfishEndpoint) (ResolvedDiscovery, error) {
+	if ep.TemplateID == "" {
+		return ResolveDiscoveryTemplate(DiscoveryTemplate{}, ep)
+	}
+	for _, t := range templates {
+		if t.ID == ep.TemplateID {
+			return ResolveDiscoveryTemplate(t, ep)
+		}
+	}
+	return ResolvedDiscovery{}, fmt.Errorf("discovery template %s not found for endpoint %s", ep.TemplateID, ep.ID)
+}
+
+// Walks reports whether the collection should be walked.
+func (r ResolvedDiscovery) Walks(c RedfishCollection) bool {
+	for _, collection := range r.Collections {
+		if collection == c {
+			return true
+		}
+	}
+	return false
+}
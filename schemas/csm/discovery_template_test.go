@@ -0,0 +1,146 @@
+package csm
+
+import (
+	"crypto/tls"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{"first retry", RetryPolicy{BackoffSeconds: schemas.Some(5)}, 1, 5 * time.Second},
+		{"doubles", RetryPolicy{BackoffSeconds: schemas.Some(5)}, 3, 20 * time.Second},
+		{"capped", RetryPolicy{BackoffSeconds: schemas.Some(5), MaxBackoffSeconds: 30}, 4, 30 * time.Second},
+		{"backoff above cap", RetryPolicy{BackoffSeconds: schemas.Some(60), MaxBackoffSeconds: 30}, 1, 30 * time.Second},
+		{"no delay", RetryPolicy{BackoffSeconds: schemas.Some(0)}, 1000000, 0},
+		{"unset uses the default", RetryPolicy{}, 1, DefaultDiscoveryBackoffSeconds * time.Second},
+		{"unbounded does not overflow", RetryPolicy{BackoffSeconds: schemas.Some(5)}, 100, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.retry); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDiscoveryTemplate(t *testing.T) {
+	template := DiscoveryTemplate{
+		ID:            "river",
+		User:          "admin",
+		CredentialRef: &CredentialRef{Store: "vault", Path: "bmc/river"},
+		UseSSDP:       schemas.Some(true),
+		MacRequired:   schemas.Some(true),
+		Collections:   []RedfishCollection{CollectionSystems},
+	}
+	tests := []struct {
+		name        string
+		ep          RedfishEndpoint
+		user        string
+		ref         bool
+		useSSDP     bool
+		macRequired bool
+	}{
+		{"template defaults", RedfishEndpoint{ID: "x3000c0s1b0", TemplateID: "river"}, "admin", true, true, true},
+		{"endpoint overrides", RedfishEndpoint{ID: "x3000c0s1b0", TemplateID: "river", User: "root", Password: "hunter2", UseSSDP: schemas.Some(false), MacRequired: schemas.Some(false)}, "root", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ResolveDiscovery([]DiscoveryTemplate{template}, tt.ep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ep := r.Endpoint
+			if ep.User != tt.user || (ep.CredentialRef != nil) != tt.ref {
+				t.Errorf("User %s CredentialRef %v, want %s and %v", ep.User, ep.CredentialRef, tt.user, tt.ref)
+			}
+			if ep.UseSSDP != schemas.Some(tt.useSSDP) || ep.MacRequired != schemas.Some(tt.macRequired) {
+				t.Errorf("UseSSDP %v MacRequired %v, want %v and %v", ep.UseSSDP, ep.MacRequired, tt.useSSDP, tt.macRequired)
+			}
+			if !r.Walks(CollectionSystems) || r.Walks(CollectionMemory) {
+				t.Errorf("Collections = %v", r.Collections)
+			}
+		})
+	}
+}
+
+func TestResolveDiscoveryDefaults(t *testing.T) {
+	r, err := ResolveDiscovery(nil, RedfishEndpoint{ID: "x1000c0s0b0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Timeout != DefaultDiscoveryTimeoutSeconds*time.Second || r.Retry.MaxAttempts != DefaultDiscoveryMaxAttempts || len(r.Collections) != len(DefaultRedfishCollections) {
+		t.Errorf("ResolveDiscovery() = %+v, want the defaults", r)
+	}
+	if r.Retry.BackoffSeconds != schemas.Some(DefaultDiscoveryBackoffSeconds) {
+		t.Errorf("BackoffSeconds = %v, want %d", r.Retry.BackoffSeconds, DefaultDiscoveryBackoffSeconds)
+	}
+	if r.Endpoint.UseSSDP != schemas.Some(false) {
+		t.Errorf("UseSSDP = %v, want false", r.Endpoint.UseSSDP)
+	}
+
+	// An explicit 0 is kept rather than replaced by the default.
+	zero := DiscoveryTemplate{ID: "river", TimeoutSeconds: schemas.Some(0), Retry: RetryPolicy{BackoffSeconds: schemas.Some(0)}}
+	if r, err := ResolveDiscoveryTemplate(zero, RedfishEndpoint{ID: "x1000c0s0b0"}); err != nil || r.Timeout != 0 || r.Retry.BackoffSeconds != schemas.Some(0) {
+		t.Errorf("ResolveDiscoveryTemplate() with zeroes = %+v, %v", r, err)
+	}
+
+	if _, err := ResolveDiscovery(nil, RedfishEndpoint{ID: "x1000c0s0b0", TemplateID: "missing"}); err == nil {
+		t.Error("ResolveDiscovery() with a missing template succeeded")
+	}
+	if _, err := ResolveDiscoveryTemplate(DiscoveryTemplate{ID: "a"}, RedfishEndpoint{TemplateID: "b"}); err == nil {
+		t.Error("ResolveDiscoveryTemplate() with another endpoint's template succeeded")
+	}
+}
+
+func TestDiscoveryTemplateValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template DiscoveryTemplate
+		ok       bool
+	}{
+		{"valid", DiscoveryTemplate{ID: "river", TLS: TLSPolicy{MinVersion: "1.2"}}, true},
+		{"no ID", DiscoveryTemplate{}, false},
+		{"bad credential reference", DiscoveryTemplate{ID: "river", CredentialRef: &CredentialRef{}}, false},
+		{"bad TLS version", DiscoveryTemplate{ID: "river", TLS: TLSPolicy{MinVersion: "2.0"}}, false},
+		{"bad collection", DiscoveryTemplate{ID: "river", Collections: []RedfishCollection{"Fans"}}, false},
+		{"negative timeout", DiscoveryTemplate{ID: "river", TimeoutSeconds: schemas.Some(-1)}, false},
+		{"negative retry", DiscoveryTemplate{ID: "river", Retry: RetryPolicy{BackoffSeconds: schemas.Some(-1)}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.template.Valid(); (err == nil) != tt.ok {
+				t.Errorf("Valid() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTLSPolicyConfig(t *testing.T) {
+	config, err := TLSPolicy{InsecureSkipVerify: true, MinVersion: "1.3"}.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.InsecureSkipVerify || config.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Config() = %+v", config)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (TLSPolicy{CABundle: empty}).Config(); err == nil {
+		t.Error("Config() with no certificates in the CA bundle succeeded")
+	}
+}
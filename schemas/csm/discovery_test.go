@@ -0,0 +1,48 @@
+package csm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiscoveryStatusUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data string
+		want DiscoveryStatus
+		ok   bool
+	}{
+		{`"DiscoverOK"`, DiscoveryOK, true},
+		{`""`, "", true},
+		{`"Discovered"`, "", false},
+		{`1`, "", false},
+	}
+	for _, tt := range tests {
+		var got DiscoveryStatus
+		err := json.Unmarshal([]byte(tt.data), &got)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q and ok %v", tt.data, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDiscoveryStatusClassification(t *testing.T) {
+	tests := []struct {
+		status    DiscoveryStatus
+		success   bool
+		retryable bool
+		terminal  bool
+	}{
+		{DiscoveryOK, true, false, true},
+		{DiscoveryHTTPsGetFailed, false, true, false},
+		{DiscoveryEPResponseFailedDecode, false, true, false},
+		{DiscoveryVerificationFailed, false, false, true},
+		{DiscoveryEndpointInvalid, false, false, true},
+		{DiscoveryNotYetQueried, false, false, false},
+	}
+	for _, tt := range tests {
+		if tt.status.IsSuccess() != tt.success || tt.status.IsRetryable() != tt.retryable || tt.status.IsTerminal() != tt.terminal {
+			t.Errorf("%s: success %v retryable %v terminal %v, want %v %v %v", tt.status,
+				tt.status.IsSuccess(), tt.status.IsRetryable(), tt.status.IsTerminal(), tt.success, tt.retryable, tt.terminal)
+		}
+	}
+}
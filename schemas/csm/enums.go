@@ -0,0 +1,157 @@
+package csm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// enumRegistry holds the accepted values of a string enum in the order they
+// were registered.  Both the Valid() helpers and the JSONSchema() enum lists
+// are driven from it, so values registered at startup are honored by both.
+type enumRegistry struct {
+	mu     sync.RWMutex
+	values []string
+	known  map[string]bool
+}
+
+func newEnumRegistry(values ...string) *enumRegistry {
+	r := &enumRegistry{known: make(map[string]bool, len(values))}
+	for _, v := range values {
+		r.add(v)
+	}
+	return r
+}
+
+func (r *enumRegistry) add(value string) {
+	if r.known[value] {
+		return
+	}
+	r.known[value] = true
+	r.values = append(r.values, value)
+}
+
+// checkEnumValues returns an error if any value is empty or has leading or
+// trailing white space.
+func checkEnumValues(values []string) error {
+	for _, v := range values {
+		if v == "" || strings.TrimSpace(v) != v {
+			return fmt.Errorf("invalid enum value %q", v)
+		}
+	}
+	return nil
+}
+
+func (r *enumRegistry) register(values ...string) error {
+	if err := checkEnumValues(values); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		r.add(v)
+	}
+	return nil
+}
+
+func (r *enumRegistry) contains(value string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.known[value]
+}
+
+// validate returns an error unless value is registered.  kind names the enum
+// in the error, e.g. "component type".
+func (r *enumRegistry) validate(kind, value string) error {
+	if !r.contains(value) {
+		return fmt.Errorf("unknown %s %q", kind, value)
+	}
+	return nil
+}
+
+func (r *enumRegistry) enum() []interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	enum := make([]interface{}, len(r.values))
+	for i, v := range r.values {
+		enum[i] = v
+	}
+	return enum
+}
+
+// enumStrings converts enum values to the strings held by a registry.
+func enumStrings[T ~string](values []T) []string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return s
+}
+
+// RegisterComponentTypes adds site-specific component types to the set of
+// accepted values.  Registering a value that is already known is a no-op.
+func RegisterComponentTypes(types ...ComponentType) error {
+	return componentTypes.register(enumStrings(types)...)
+}
+
+// RegisterComponentRoles adds site-specific roles such as "UAN" or "LNet" to
+// the set of accepted values.
+func RegisterComponentRoles(roles ...ComponentRole) error {
+	return componentRoles.register(enumStrings(roles)...)
+}
+
+// RegisterComponentSubRoles adds site-specific sub-roles such as "Gateway" to
+// the set of accepted values.
+func RegisterComponentSubRoles(subRoles ...ComponentSubRole) error {
+	return componentSubRoles.register(enumStrings(subRoles)...)
+}
+
+// EnumExtensions lists the site-specific enum values to register, typically
+// loaded from a YAML or JSON file with LoadEnumExtensions.
+type EnumExtensions struct {
+	Types    []ComponentType    `json:"types,omitempty" yaml:"types,omitempty"`
+	Roles    []ComponentRole    `json:"roles,omitempty" yaml:"roles,omitempty"`
+	SubRoles []ComponentSubRole `json:"subroles,omitempty" yaml:"subroles,omitempty"`
+}
+
+// Register adds all of the extension values to their registries.  Every
+// value is checked first, so on error nothing is registered.
+func (e EnumExtensions) Register() error {
+	batches := []struct {
+		kind     string
+		registry *enumRegistry
+		values   []string
+	}{
+		{"component types", componentTypes, enumStrings(e.Types)},
+		{"roles", componentRoles, enumStrings(e.Roles)},
+		{"sub-roles", componentSubRoles, enumStrings(e.SubRoles)},
+	}
+	for _, b := range batches {
+		if err := checkEnumValues(b.values); err != nil {
+			return fmt.Errorf("failed to register %s: %w", b.kind, err)
+		}
+	}
+	for _, b := range batches {
+		if err := b.registry.register(b.values...); err != nil {
+			return fmt.Errorf("failed to register %s: %w", b.kind, err)
+		}
+	}
+	return nil
+}
+
+// LoadEnumExtensions reads an EnumExtensions file and registers its values.
+// The file may be YAML or JSON.  If any value is invalid, none are registered.
+func LoadEnumExtensions(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read enum extensions: %w", err)
+	}
+	var ext EnumExtensions
+	if err := yaml.Unmarshal(data, &ext); err != nil {
+		return fmt.Errorf("failed to parse enum extensions %s: %w", path, err)
+	}
+	return ext.Register()
+}
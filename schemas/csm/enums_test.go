@@ -0,0 +1,104 @@
+package csm
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestComponentEnumValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid func() error
+		ok    bool
+	}{
+		{"known type", ComponentType("Node").Valid, true},
+		{"unknown type", ComponentType("Toaster").Valid, false},
+		{"known role", RoleCompute.Valid, true},
+		{"unknown role", ComponentRole("Nonexistent").Valid, false},
+		{"known state", StateReady.Valid, true},
+		{"empty state", ComponentState("").Valid, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.valid(); (err == nil) != tt.ok {
+				t.Errorf("Valid() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRegisterComponentRoles(t *testing.T) {
+	role := ComponentRole("TestRegisteredRole")
+	if err := role.Valid(); err == nil {
+		t.Fatalf("%s is valid before it is registered", role)
+	}
+	if err := RegisterComponentRoles(role); err != nil {
+		t.Fatal(err)
+	}
+	if err := role.Valid(); err != nil {
+		t.Errorf("Valid() after registering = %v", err)
+	}
+	if !slices.Contains(ComponentRole("").JSONSchema().Enum, interface{}(string(role))) {
+		t.Errorf("JSONSchema() enum does not include %s", role)
+	}
+	// Registering again is a no-op.
+	if err := RegisterComponentRoles(role); err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, v := range ComponentRole("").JSONSchema().Enum {
+		if v == string(role) {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("%s appears %d times in the enum", role, count)
+	}
+}
+
+func TestRegisterRejectsInvalidValues(t *testing.T) {
+	for _, v := range []ComponentSubRole{"", " Padded"} {
+		if err := RegisterComponentSubRoles(v); err == nil {
+			t.Errorf("RegisterComponentSubRoles(%q) succeeded", v)
+		}
+	}
+}
+
+func TestLoadEnumExtensions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "enums.yaml")
+	data := "roles: [TestYAMLRole]\nsubroles: [TestYAMLSubRole]\ntypes: [TestYAMLType]\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadEnumExtensions(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range []error{
+		ComponentRole("TestYAMLRole").Valid(),
+		ComponentSubRole("TestYAMLSubRole").Valid(),
+		ComponentType("TestYAMLType").Valid(),
+	} {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLoadEnumExtensionsRegistersNothingOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "enums.yaml")
+	data := "types: [TestBatchType]\nroles: [TestBatchRole]\nsubroles: [\" Padded\"]\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadEnumExtensions(path); err == nil {
+		t.Fatal("LoadEnumExtensions() with an invalid sub-role succeeded")
+	}
+	if err := ComponentType("TestBatchType").Valid(); err == nil {
+		t.Error("component type registered despite the invalid sub-role")
+	}
+	if err := ComponentRole("TestBatchRole").Valid(); err == nil {
+		t.Error("role registered despite the invalid sub-role")
+	}
+}
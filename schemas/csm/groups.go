@@ -0,0 +1,234 @@
+package csm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Members lists the xnames belonging to a group or partition.
+type Members struct {
+	IDs []string `json:"ids" jsonschema:"description=Xnames of the member components"`
+}
+
+// Group is a named collection of components.  Components may belong to any
+// number of groups, except that groups sharing an ExclusiveGroup may not have
+// members in common.
+type Group struct {
+	Label          string   `json:"label" jsonschema:"description=Unique name of the group,pattern=^[a-z0-9][a-z0-9_.:-]*$"`
+	Description    string   `json:"description,omitempty"`
+	Tags           []string `json:"tags,omitempty" jsonschema:"description=Free-form labels used to find groups"`
+	ExclusiveGroup string   `json:"exclusiveGroup,omitempty" jsonschema:"description=Name of the exclusive set this group belongs to. A component may be a member of at most one group in the set.,pattern=^[a-z0-9][a-z0-9_.:-]*$"`
+	Members        Members  `json:"members"`
+}
+
+// Partition is a hard division of the system.  A component may belong to at
+// most one partition.
+type Partition struct {
+	Name        string   `json:"name" jsonschema:"description=Unique name of the partition,pattern=^[a-z0-9][a-z0-9_.:-]*$"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" jsonschema:"description=Free-form labels used to find partitions"`
+	Members     Members  `json:"members"`
+}
+
+// Membership lists the groups and partition a single component belongs to.
+type Membership struct {
+	ID            string   `json:"id" jsonschema:"description=Xname"`
+	GroupLabels   []string `json:"groupLabels"`
+	PartitionName string   `json:"partitionName,omitempty"`
+}
+
+var collectionNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]*$`)
+
+func (g Group) Valid() error {
+	if !collectionNameRegex.MatchString(g.Label) {
+		return fmt.Errorf("invalid group label %q", g.Label)
+	}
+	if g.ExclusiveGroup != "" && !collectionNameRegex.MatchString(g.ExclusiveGroup) {
+		return fmt.Errorf("group %s has invalid exclusive group %q", g.Label, g.ExclusiveGroup)
+	}
+	if err := g.Members.valid(); err != nil {
+		return fmt.Errorf("group %s: %w", g.Label, err)
+	}
+	return nil
+}
+
+func (p Partition) Valid() error {
+	if !collectionNameRegex.MatchString(p.Name) {
+		return fmt.Errorf("invalid partition name %q", p.Name)
+	}
+	if err := p.Members.valid(); err != nil {
+		return fmt.Errorf("partition %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+func (m Members) valid() error {
+	seen := make(map[string]bool, len(m.IDs))
+	for _, id := range m.IDs {
+		if XnameSegments(id) == nil {
+			return fmt.Errorf("invalid member xname %q", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate member %s", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ValidateMemberships checks every group and partition, and that no component
+// is in more than one partition or more than one group of an exclusive set.
+// All problems found are returned together.
+func ValidateMemberships(groups []Group, partitions []Partition) error {
+	var errs []error
+
+	labels := make(map[string]bool, len(groups))
+	exclusive := make(map[string]map[string]string) // exclusive group -> xname -> group label
+	for _, g := range groups {
+		if err := g.Valid(); err != nil {
+			errs = append(errs, err)
+		}
+		if labels[g.Label] {
+			errs = append(errs, fmt.Errorf("duplicate group label %s", g.Label))
+		}
+		labels[g.Label] = true
+		if g.ExclusiveGroup == "" {
+			continue
+		}
+		owners := exclusive[g.ExclusiveGroup]
+		if owners == nil {
+			owners = make(map[string]string)
+			exclusive[g.ExclusiveGroup] = owners
+		}
+		for _, id := range g.Members.IDs {
+			if other, ok := owners[id]; ok && other != g.Label {
+				errs = append(errs, fmt.Errorf("%s is in groups %s and %s of exclusive group %s", id, other, g.Label, g.ExclusiveGroup))
+				continue
+			}
+			owners[id] = g.Label
+		}
+	}
+
+	names := make(map[string]bool, len(partitions))
+	partitionOf := make(map[string]string)
+	for _, p := range partitions {
+		if err := p.Valid(); err != nil {
+			errs = append(errs, err)
+		}
+		if names[p.Name] {
+			errs = append(errs, fmt.Errorf("duplicate partition name %s", p.Name))
+		}
+		names[p.Name] = true
+		for _, id := range p.Members.IDs {
+			if other, ok := partitionOf[id]; ok && other != p.Name {
+				errs = append(errs, fmt.Errorf("%s is in partitions %s and %s", id, other, p.Name))
+				continue
+			}
+			partitionOf[id] = p.Name
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Memberships inverts groups and partitions into per-component memberships,
+// ordered by xname.
+func Memberships(groups []Group, partitions []Partition) []Membership {
+	byID := make(map[string]*Membership)
+	get := func(id string) *Membership {
+		m, ok := byID[id]
+		if !ok {
+			m = &Membership{ID: id, GroupLabels: []string{}}
+			byID[id] = m
+		}
+		return m
+	}
+	for _, g := range groups {
+		for _, id := range g.Members.IDs {
+			m := get(id)
+			m.GroupLabels = append(m.GroupLabels, g.Label)
+		}
+	}
+	for _, p := range partitions {
+		for _, id := range p.Members.IDs {
+			get(id).PartitionName = p.Name
+		}
+	}
+
+	memberships := make([]Membership, 0, len(byID))
+	for _, m := range byID {
+		sort.Strings(m.GroupLabels)
+		memberships = append(memberships, *m)
+	}
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].ID < memberships[j].ID })
+	return memberships
+}
+
+// MemberSet is a set of xnames used to combine group and partition memberships.
+type MemberSet map[string]struct{}
+
+func NewMemberSet(ids ...string) MemberSet {
+	s := make(MemberSet, len(ids))
+	for _, id := range ids {
+		s[id] = struct{}{}
+	}
+	return s
+}
+
+// Set returns the members as a MemberSet.
+func (m Members) Set() MemberSet {
+	return NewMemberSet(m.IDs...)
+}
+
+func (s MemberSet) Contains(id string) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s MemberSet) Union(o MemberSet) MemberSet {
+	u := make(MemberSet, len(s)+len(o))
+	for id := range s {
+		u[id] = struct{}{}
+	}
+	for id := range o {
+		u[id] = struct{}{}
+	}
+	return u
+}
+
+func (s MemberSet) Intersection(o MemberSet) MemberSet {
+	i := make(MemberSet)
+	for id := range s {
+		if o.Contains(id) {
+			i[id] = struct{}{}
+		}
+	}
+	return i
+}
+
+func (s MemberSet) Difference(o MemberSet) MemberSet {
+	d := make(MemberSet)
+	for id := range s {
+		if !o.Contains(id) {
+			d[id] = struct{}{}
+		}
+	}
+	return d
+}
+
+// IDs returns the xnames in the set in sorted order.
+func (s MemberSet) IDs() []string {
+	ids := make([]string, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Members converts the set back into a Members list.
+func (s MemberSet) Members() Members {
+	return Members{IDs: s.IDs()}
+}
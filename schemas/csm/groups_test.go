@@ -0,0 +1,106 @@
+package csm
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		group Group
+		ok    bool
+	}{
+		{"valid", Group{Label: "compute", ExclusiveGroup: "role", Members: Members{IDs: []string{"x1000c0s0b0n0"}}}, true},
+		{"empty", Group{Label: "empty"}, true},
+		{"uppercase label", Group{Label: "Compute"}, false},
+		{"bad exclusive group", Group{Label: "compute", ExclusiveGroup: "-role"}, false},
+		{"bad member", Group{Label: "compute", Members: Members{IDs: []string{"node1 "}}}, false},
+		{"duplicate member", Group{Label: "compute", Members: Members{IDs: []string{"x1000c0s0b0n0", "x1000c0s0b0n0"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.group.Valid(); (err == nil) != tt.ok {
+				t.Errorf("Valid() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateMemberships(t *testing.T) {
+	groups := []Group{
+		{Label: "blue", ExclusiveGroup: "colour", Members: Members{IDs: []string{"x1000c0s0b0n0", "x1000c0s0b0n1"}}},
+		{Label: "red", ExclusiveGroup: "colour", Members: Members{IDs: []string{"x1000c0s0b0n1"}}},
+		{Label: "gpu", Members: Members{IDs: []string{"x1000c0s0b0n0"}}},
+		{Label: "gpu"},
+	}
+	partitions := []Partition{
+		{Name: "p1", Members: Members{IDs: []string{"x1000c0s0b0n0"}}},
+		{Name: "p2", Members: Members{IDs: []string{"x1000c0s0b0n0", "x1000c0s0b0n1"}}},
+	}
+	err := ValidateMemberships(groups, partitions)
+	if err == nil {
+		t.Fatal("ValidateMemberships() succeeded")
+	}
+	for _, want := range []string{
+		"x1000c0s0b0n1 is in groups blue and red of exclusive group colour",
+		"duplicate group label gpu",
+		"x1000c0s0b0n0 is in partitions p1 and p2",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+
+	if err := ValidateMemberships(groups[:1], partitions[:1]); err != nil {
+		t.Errorf("ValidateMemberships() of consistent memberships = %v", err)
+	}
+}
+
+func TestMemberships(t *testing.T) {
+	groups := []Group{
+		{Label: "gpu", Members: Members{IDs: []string{"x1000c0s0b0n1"}}},
+		{Label: "compute", Members: Members{IDs: []string{"x1000c0s0b0n1", "x1000c0s0b0n0"}}},
+	}
+	partitions := []Partition{{Name: "p1", Members: Members{IDs: []string{"x1000c0s0b0n0"}}}}
+
+	got := Memberships(groups, partitions)
+	want := []Membership{
+		{ID: "x1000c0s0b0n0", GroupLabels: []string{"compute"}, PartitionName: "p1"},
+		{ID: "x1000c0s0b0n1", GroupLabels: []string{"compute", "gpu"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Memberships() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].PartitionName != want[i].PartitionName || !slices.Equal(got[i].GroupLabels, want[i].GroupLabels) {
+			t.Errorf("Memberships()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMemberSet(t *testing.T) {
+	a := NewMemberSet("x1000c0s0b0n0", "x1000c0s0b0n1")
+	b := Members{IDs: []string{"x1000c0s0b0n1", "x1000c0s0b0n2"}}.Set()
+
+	tests := []struct {
+		name string
+		set  MemberSet
+		want []string
+	}{
+		{"union", a.Union(b), []string{"x1000c0s0b0n0", "x1000c0s0b0n1", "x1000c0s0b0n2"}},
+		{"intersection", a.Intersection(b), []string{"x1000c0s0b0n1"}},
+		{"difference", a.Difference(b), []string{"x1000c0s0b0n0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.set.Members().IDs; !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !a.Contains("x1000c0s0b0n0") || a.Contains("x1000c0s0b0n2") {
+		t.Error("Contains() is wrong")
+	}
+}
@@ -1,15 +1,28 @@
 package csm
 
 import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/invopop/jsonschema"
+	"github.com/openchami/schemas/schemas"
 )
 
 type DiscoveryInfo struct {
-	LastAttempt    time.Time `json:"LastAttempt,omitempty" jsonschema:"description=The time the last discovery attempt took place,format=date-time,readOnly=true"`
-	LastStatus     string    `json:"LastStatus,omitempty" jsonschema:"description=Describes the outcome of the last discovery attempt,enum=EndpointInvalid,enum=EPResponseFailedDecode,enum=HTTPsGetFailed,enum=NotYetQueried,enum=VerificationFailed,enum=ChildVerificationFailed,enum=DiscoverOK,readOnly=true"`
-	RedfishVersion string    `json:"RedfishVersion,omitempty" jsonschema:"description=Version of Redfish as reported by the RF service root,readOnly=true"`
+	LastAttempt    time.Time       `json:"LastAttempt,omitempty" jsonschema:"description=The time the last discovery attempt took place,format=date-time,readOnly=true"`
+	LastStatus     DiscoveryStatus `json:"LastStatus,omitempty" jsonschema:"description=Describes the outcome of the last discovery attempt"`
+	RedfishVersion string          `json:"RedfishVersion,omitempty" jsonschema:"description=Version of Redfish as reported by the RF service root,readOnly=true"`
+}
+
+// JSONSchemaExtend marks LastStatus read-only, which cannot be expressed in
+// the tag of a field whose type provides its own schema.
+func (DiscoveryInfo) JSONSchemaExtend(s *jsonschema.Schema) {
+	if p, ok := s.Properties.Get("LastStatus"); ok {
+		p.ReadOnly = true
+	}
 }
 
 type RedfishDiscovery struct {
@@ -18,27 +31,184 @@ type RedfishDiscovery struct {
 	URI          string          `json:"EndpointID,omitempty" jsonschema:"description=ID of the endpoint that was discovered"`
 	Attempted    time.Time       `json:"Attempted,omitempty" jsonschema:"description=Time the discovery was started,format=date-time"`
 	Completed    time.Time       `json:"Completed,omitempty" jsonschema:"description=Time the discovery was completed,format=date-time"`
-	Status       string          `json:"Status,omitempty" jsonschema:"description=Describes the outcome of the discovery attempt,enum=EndpointInvalid,enum=EPResponseFailedDecode,enum=HTTPsGetFailed,enum=NotYetQueried,enum=VerificationFailed,enum=ChildVerificationFailed,enum=DiscoverOK"`
+	Status       DiscoveryStatus `json:"Status,omitempty" jsonschema:"description=Describes the outcome of the discovery attempt"`
 	Payload      RedfishEndpoint `json:"Payload,omitempty" jsonschema:"description=The discovered endpoint"`
 }
 
 type RedfishEndpoint struct {
-	ID                 string        `json:"ID" jsonschema:"description=HMS Logical component type e.g. NodeBMC, ChassisBMC.,$ref=#/definitions/HMSType.1.0.0"`
-	Type               ComponentType `json:"Type,omitempty"`
-	Name               string        `json:"Name,omitempty" jsonschema:"description=This is an arbitrary, user-provided name for the endpoint. It can describe anything that is not captured by the ID/xname."`
-	Hostname           string        `json:"Hostname,omitempty" jsonschema:"description=Hostname of the endpoint's FQDN, will always be the host portion of the fully-qualified domain name. Note that the hostname should normally always be the same as the ID field (i.e. xname) of the endpoint."`
-	Domain             string        `json:"Domain,omitempty" jsonschema:"description=Domain of the endpoint's FQDN. Will always match remaining non-hostname portion of fully-qualified domain name (FQDN)."`
-	FQDN               string        `json:"FQDN,omitempty" jsonschema:"description=Fully-qualified domain name of RF endpoint on management network. This is not writable because it is made up of the Hostname and Domain."`
-	Enabled            bool          `json:"Enabled,omitempty" jsonschema:"description=To disable a component without deleting its data from the database, can be set to false,example=true"`
-	URI                string        `json:"URI,omitempty" jsonschema:"description=URI of the Redfish service root"`
-	UID                uuid.UUID     `json:"UUID,omitempty" jsonschema:"$ref=#/definitions/UUID.1.0.0"`
-	User               string        `json:"User,omitempty" jsonschema:"description=Username to use when interrogating endpoint"`
-	Password           string        `json:"Password,omitempty" jsonschema:"description=Password to use when interrogating endpoint, normally suppressed in output."`
-	UseSSDP            bool          `json:"UseSSDP,omitempty" jsonschema:"description=Whether to use SSDP for discovery if the EP supports it."`
-	MacRequired        bool          `json:"MacRequired,omitempty" jsonschema:"description=Whether the MAC must be used (e.g. in River) in setting up geolocation info so the endpoint's location in the system can be determined. The MAC does not need to be provided when creating the endpoint if the endpoint type can arrive at a geolocated hostname on its own."`
-	MACAddr            string        `json:"MACAddr,omitempty" jsonschema:"description=This is the MAC on the of the Redfish Endpoint on the management network, i.e. corresponding to the FQDN field's Ethernet interface where the root service is running. Not the HSN MAC. This is a MAC address in the standard colon-separated 12 byte hex format.,pattern=^([0-9A-Fa-f]{2}[:-]){5}([0-9A-Fa-f]{2})$,example=ae:12:e2:ff:89:9d"`
-	IPAddress          string        `json:"IPAddress,omitempty" jsonschema:"description=This is the IP of the Redfish Endpoint on the management network, i.e. corresponding to the FQDN field's Ethernet interface where the root service is running. This may be IPv4 or IPv6,example=10.254.2.10"`
-	RediscoverOnUpdate bool          `json:"RediscoverOnUpdate,omitempty" jsonschema:"description=Trigger a rediscovery when endpoint info is updated."`
-	TemplateID         string        `json:"TemplateID,omitempty" jsonschema:"description=Links to a discovery template defining how the endpoint should be discovered."`
-	DiscoveryInfo      DiscoveryInfo `json:"DiscoveryInfo,omitempty" jsonschema:"description=Contains info about the discovery status of the given endpoint,readOnly=true"`
+	ID                 string                 `json:"ID" jsonschema:"description=HMS Logical component type e.g. NodeBMC, ChassisBMC.,$ref=#/definitions/HMSType.1.0.0"`
+	Type               ComponentType          `json:"Type,omitempty"`
+	Name               string                 `json:"Name,omitempty" jsonschema:"description=This is an arbitrary, user-provided name for the endpoint. It can describe anything that is not captured by the ID/xname."`
+	Hostname           string                 `json:"Hostname,omitempty" jsonschema:"description=Hostname of the endpoint's FQDN, will always be the host portion of the fully-qualified domain name. Note that the hostname should normally always be the same as the ID field (i.e. xname) of the endpoint."`
+	Domain             string                 `json:"Domain,omitempty" jsonschema:"description=Domain of the endpoint's FQDN. Will always match remaining non-hostname portion of fully-qualified domain name (FQDN)."`
+	FQDN               string                 `json:"FQDN,omitempty" jsonschema:"description=Fully-qualified domain name of RF endpoint on management network. This is not writable because it is made up of the Hostname and Domain."`
+	Enabled            schemas.Optional[bool] `json:"Enabled,omitempty,omitzero" jsonschema:"description=To disable a component without deleting its data from the database, can be set to false,example=true"`
+	URI                string                 `json:"URI,omitempty" jsonschema:"description=URI of the Redfish service root"`
+	UID                uuid.UUID              `json:"UUID,omitempty" jsonschema:"$ref=#/definitions/UUID.1.0.0"`
+	User               string                 `json:"User,omitempty" jsonschema:"description=Username to use when interrogating endpoint"`
+	Password           string                 `json:"Password,omitempty" jsonschema:"description=Password to use when interrogating endpoint. Omitted from output unless marshaled with CredentialsFull.,writeOnly=true"`
+	CredentialRef      *CredentialRef         `json:"CredentialRef,omitempty" jsonschema:"description=Reference to credentials held in a secret store, used instead of embedding the Password."`
+	UseSSDP            schemas.Optional[bool] `json:"UseSSDP,omitempty,omitzero" jsonschema:"description=Whether to use SSDP for discovery if the EP supports it. Unset uses the discovery template's setting."`
+	MacRequired        schemas.Optional[bool] `json:"MacRequired,omitempty,omitzero" jsonschema:"description=Whether the MAC must be used (e.g. in River) in setting up geolocation info so the endpoint's location in the system can be determined. The MAC does not need to be provided when creating the endpoint if the endpoint type can arrive at a geolocated hostname on its own. Unset uses the discovery template's setting."`
+	MACAddr            schemas.MACAddress     `json:"MACAddr,omitempty,omitzero" jsonschema:"description=This is the MAC on the of the Redfish Endpoint on the management network, i.e. corresponding to the FQDN field's Ethernet interface where the root service is running. Not the HSN MAC. This is a MAC address in the standard colon-separated 12 byte hex format."`
+	IPAddress          schemas.IPAddress      `json:"IPAddress,omitempty,omitzero" jsonschema:"description=This is the IP of the Redfish Endpoint on the management network, i.e. corresponding to the FQDN field's Ethernet interface where the root service is running. This may be IPv4 or IPv6"`
+	RediscoverOnUpdate schemas.Optional[bool] `json:"RediscoverOnUpdate,omitempty,omitzero" jsonschema:"description=Trigger a rediscovery when endpoint info is updated."`
+	TemplateID         string                 `json:"TemplateID,omitempty" jsonschema:"description=Links to a discovery template defining how the endpoint should be discovered."`
+	DiscoveryInfo      DiscoveryInfo          `json:"DiscoveryInfo,omitempty" jsonschema:"description=Contains info about the discovery status of the given endpoint,readOnly=true"`
+	TLSInfo            *TLSInfo               `json:"TLSInfo,omitempty" jsonschema:"description=Certificate and TLS connection details last observed for the endpoint"`
+}
+
+// CredentialRef points to the credentials for an endpoint in a secret store,
+// so that the endpoint record never has to carry the password itself.
+type CredentialRef struct {
+	Store       string `json:"Store,omitempty" jsonschema:"description=Name of the secret store holding the credentials e.g. vault"`
+	Path        string `json:"Path" jsonschema:"description=Path of the secret within the store"`
+	UserKey     string `json:"UserKey,omitempty" jsonschema:"description=Key of the username within the secret,default=username"`
+	PasswordKey string `json:"PasswordKey,omitempty" jsonschema:"description=Key of the password within the secret,default=password"`
+}
+
+func (r CredentialRef) Valid() error {
+	if r.Path == "" {
+		return fmt.Errorf("credential reference has no path")
+	}
+	return nil
+}
+
+func (r CredentialRef) String() string {
+	if r.Store == "" {
+		return r.Path
+	}
+	return r.Store + ":" + r.Path
+}
+
+// ValidCredentials checks that the endpoint does not both embed a password and
+// reference one in a secret store.
+func (ep RedfishEndpoint) ValidCredentials() error {
+	if ep.CredentialRef == nil {
+		return nil
+	}
+	if ep.Password != "" {
+		return fmt.Errorf("endpoint %s has both a Password and a CredentialRef", ep.ID)
+	}
+	return ep.CredentialRef.Valid()
+}
+
+// CredentialMode selects whether secrets are included when marshaling.
+type CredentialMode int
+
+const (
+	CredentialsRedacted CredentialMode = iota // Secrets are omitted.  Use for APIs and logs.
+	CredentialsFull                           // Secrets are included.  Use only for secure storage.
+)
+
+const redacted = "[REDACTED]"
+
+// MarshalJSON marshals the endpoint with its password omitted.  Use
+// MarshalJSONMode with CredentialsFull to include it, or FullCredentials when
+// the endpoint is nested in another value.
+func (ep RedfishEndpoint) MarshalJSON() ([]byte, error) {
+	return ep.MarshalJSONMode(CredentialsRedacted)
+}
+
+// MarshalJSONMode marshals the endpoint, including the password only when mode
+// is CredentialsFull.
+func (ep RedfishEndpoint) MarshalJSONMode(mode CredentialMode) ([]byte, error) {
+	type redfishEndpoint RedfishEndpoint
+	if mode != CredentialsFull {
+		ep.Password = ""
+	}
+	return json.Marshal(redfishEndpoint(ep))
+}
+
+// MarshalJSONMode marshals the discovery, including the password of its
+// Payload only when mode is CredentialsFull.
+func (d RedfishDiscovery) MarshalJSONMode(mode CredentialMode) ([]byte, error) {
+	type redfishDiscovery RedfishDiscovery
+	payload, err := d.Payload.MarshalJSONMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		redfishDiscovery
+		Payload json.RawMessage `json:"Payload,omitempty"`
+	}{redfishDiscovery(d), payload})
+}
+
+// RedfishEndpoints is a list of endpoints that can be marshaled with a
+// CredentialMode.
+type RedfishEndpoints []RedfishEndpoint
+
+// MarshalJSONMode marshals the endpoints, including their passwords only
+// when mode is CredentialsFull.
+func (eps RedfishEndpoints) MarshalJSONMode(mode CredentialMode) ([]byte, error) {
+	if eps == nil {
+		return []byte("null"), nil
+	}
+	items := make([]json.RawMessage, len(eps))
+	for i, ep := range eps {
+		data, err := ep.MarshalJSONMode(mode)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = data
+	}
+	return json.Marshal(items)
+}
+
+// CredentialMarshaler is implemented by values holding endpoints that can be
+// marshaled with their passwords.
+type CredentialMarshaler interface {
+	MarshalJSONMode(mode CredentialMode) ([]byte, error)
+}
+
+// FullCredentials marshals the value it wraps with CredentialsFull, so that
+// endpoints nested in a discovery or a list keep their passwords wherever the
+// wrapper itself is nested.  Use only for secure storage.
+type FullCredentials struct {
+	Value CredentialMarshaler
+}
+
+func (f FullCredentials) MarshalJSON() ([]byte, error) {
+	if f.Value == nil {
+		return []byte("null"), nil
+	}
+	return f.Value.MarshalJSONMode(CredentialsFull)
+}
+
+// String summarizes the endpoint without revealing its password.
+func (ep RedfishEndpoint) String() string {
+	password := ""
+	if ep.Password != "" {
+		password = redacted
+	}
+	credentialRef := ""
+	if ep.CredentialRef != nil {
+		credentialRef = ep.CredentialRef.String()
+	}
+	return fmt.Sprintf("RedfishEndpoint{ID: %s, Type: %s, FQDN: %s, URI: %s, User: %s, Password: %s, CredentialRef: %s}",
+		ep.ID, ep.Type, ep.FQDN, ep.URI, ep.User, password, credentialRef)
+}
+
+// GoString keeps %#v from printing the password.
+func (ep RedfishEndpoint) GoString() string {
+	return ep.String()
+}
+
+// LogValue implements slog.LogValuer so that logging an endpoint never
+// includes its password.
+func (ep RedfishEndpoint) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("ID", ep.ID),
+		slog.String("Type", string(ep.Type)),
+		slog.String("FQDN", ep.FQDN),
+		slog.String("URI", ep.URI),
+		slog.String("User", ep.User),
+	}
+	if ep.Password != "" {
+		attrs = append(attrs, slog.String("Password", redacted))
+	}
+	if ep.CredentialRef != nil {
+		attrs = append(attrs, slog.String("CredentialRef", ep.CredentialRef.String()))
+	}
+	return slog.GroupValue(attrs...)
 }
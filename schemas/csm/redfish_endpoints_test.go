@@ -0,0 +1,68 @@
+package csm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedfishEndpointCredentials(t *testing.T) {
+	ep := RedfishEndpoint{ID: "x1000c0s0b0", User: "root", Password: "hunter2"}
+	discovery := RedfishDiscovery{EntrypointID: "x1000c0s0b0", Payload: ep}
+	endpoints := RedfishEndpoints{ep, {ID: "x1000c0s1b0", Password: "swordfish"}}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		secrets []string
+		full    bool
+	}{
+		{"endpoint", ep, []string{"hunter2"}, false},
+		{"nested payload", discovery, []string{"hunter2"}, false},
+		{"list", []RedfishEndpoint(endpoints), []string{"hunter2", "swordfish"}, false},
+		{"full endpoint", FullCredentials{ep}, []string{"hunter2"}, true},
+		{"full nested payload", map[string]interface{}{"discovery": FullCredentials{discovery}}, []string{"hunter2"}, true},
+		{"full list", struct{ Endpoints FullCredentials }{FullCredentials{endpoints}}, []string{"hunter2", "swordfish"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range tt.secrets {
+				if strings.Contains(string(data), secret) != tt.full {
+					t.Errorf("%s: password included %v, want %v", data, !tt.full, tt.full)
+				}
+			}
+			if !strings.Contains(string(data), `"x1000c0s0b0"`) {
+				t.Errorf("%s: endpoint ID missing", data)
+			}
+		})
+	}
+}
+
+func TestRedfishDiscoveryMarshalJSONModeRoundTrip(t *testing.T) {
+	discovery := RedfishDiscovery{EntrypointID: "x1000c0s0b0", Status: DiscoveryOK, Payload: RedfishEndpoint{ID: "x1000c0s0b0", Password: "hunter2"}}
+	data, err := discovery.MarshalJSONMode(CredentialsFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got RedfishDiscovery
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.EntrypointID != discovery.EntrypointID || got.Status != discovery.Status || got.Payload.Password != "hunter2" {
+		t.Errorf("round trip = %+v, want %+v", got, discovery)
+	}
+}
+
+func TestRedfishEndpointStringRedacts(t *testing.T) {
+	ep := RedfishEndpoint{ID: "x1000c0s0b0", Password: "hunter2"}
+	for _, s := range []string{ep.String(), fmt.Sprintf("%v", ep), fmt.Sprintf("%#v", ep)} {
+		if strings.Contains(s, "hunter2") {
+			t.Errorf("%q reveals the password", s)
+		}
+	}
+}
@@ -0,0 +1,582 @@
+package csm
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invopop/jsonschema"
+	"github.com/openchami/schemas/schemas"
+)
+
+// ReservationScope describes which components a reservation or lock covers.
+type ReservationScope string
+
+const (
+	ScopeComponent ReservationScope = "Component" // Only the named component
+	ScopeSubtree   ReservationScope = "Subtree"   // The named component and every component beneath it
+)
+
+var reservationScopes = newEnumRegistry(
+	string(ScopeComponent),
+	string(ScopeSubtree),
+)
+
+func (ReservationScope) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        reservationScopes.enum(),
+		Description: "Which components a reservation or lock covers.  Defaults to Component.",
+	}
+}
+
+// Valid returns an error unless the scope is one of the known values.  An
+// empty scope is treated as Component.
+func (s ReservationScope) Valid() error {
+	if s == "" {
+		return nil
+	}
+	return reservationScopes.validate("reservation scope", string(s))
+}
+
+// ProcessingModel controls how partial failures are handled.
+type ProcessingModel string
+
+const (
+	ProcessingRigid    ProcessingModel = "Rigid"    // All components succeed or none are changed
+	ProcessingFlexible ProcessingModel = "Flexible" // Each component succeeds or fails independently
+)
+
+var processingModels = newEnumRegistry(
+	string(ProcessingRigid),
+	string(ProcessingFlexible),
+)
+
+func (ProcessingModel) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        processingModels.enum(),
+		Description: "Rigid requests succeed for all components or none.  Flexible requests are processed per component.  Defaults to Rigid.",
+	}
+}
+
+// Valid returns an error unless the processing model is one of the known
+// values.  An empty model is treated as Rigid.
+func (p ProcessingModel) Valid() error {
+	if p == "" {
+		return nil
+	}
+	return processingModels.validate("processing model", string(p))
+}
+
+// Reservation grants an owner exclusive use of a component until it expires
+// or is released.
+type Reservation struct {
+	ID             string           `json:"ID" jsonschema:"description=Xname"`
+	Owner          string           `json:"Owner" jsonschema:"description=Service or user holding the reservation"`
+	Scope          ReservationScope `json:"Scope,omitempty"`
+	ReservationKey string           `json:"ReservationKey" jsonschema:"description=Key that can be shared with other services to prove the reservation is held"`
+	DepositionKey  string           `json:"DepositionKey,omitempty" jsonschema:"description=Key known only to the owner, required to renew or release the reservation"`
+	Created        time.Time        `json:"Created" jsonschema:"format=date-time"`
+	ExpirationTime time.Time        `json:"ExpirationTime" jsonschema:"format=date-time"`
+}
+
+// Lock prevents reservations from being created or renewed on a component,
+// typically while an administrator is servicing it.  A locked component has
+// Component.Locked set and its Flag set to Locked.
+type Lock struct {
+	ID             string           `json:"ID" jsonschema:"description=Xname"`
+	Owner          string           `json:"Owner" jsonschema:"description=Service or user holding the lock"`
+	Scope          ReservationScope `json:"Scope,omitempty"`
+	Created        time.Time        `json:"Created" jsonschema:"format=date-time"`
+	ExpirationTime time.Time        `json:"ExpirationTime,omitempty" jsonschema:"description=Time the lock expires. Locks without an expiration are held until released.,format=date-time"`
+}
+
+// ReservationKey identifies a reservation by component and key.
+type ReservationKey struct {
+	ID  string `json:"ID" jsonschema:"description=Xname"`
+	Key string `json:"Key"`
+}
+
+type ReservationRequest struct {
+	IDs             []string         `json:"IDs" jsonschema:"description=Xnames of the components to reserve"`
+	Owner           string           `json:"Owner"`
+	Scope           ReservationScope `json:"Scope,omitempty"`
+	DurationMinutes int              `json:"DurationMinutes" jsonschema:"minimum=1"`
+	ProcessingModel ProcessingModel  `json:"ProcessingModel,omitempty"`
+}
+
+type ReservationRenewRequest struct {
+	Keys            []ReservationKey `json:"Keys" jsonschema:"description=Components and their deposition keys"`
+	DurationMinutes int              `json:"DurationMinutes" jsonschema:"minimum=1"`
+	ProcessingModel ProcessingModel  `json:"ProcessingModel,omitempty"`
+}
+
+type ReservationReleaseRequest struct {
+	Keys            []ReservationKey `json:"Keys" jsonschema:"description=Components and their deposition keys"`
+	ProcessingModel ProcessingModel  `json:"ProcessingModel,omitempty"`
+}
+
+// ReservationFailure explains why a component was not reserved, renewed,
+// released, locked or unlocked.
+type ReservationFailure struct {
+	ID     string `json:"ID" jsonschema:"description=Xname"`
+	Reason string `json:"Reason"`
+}
+
+type ReservationResponse struct {
+	Success []Reservation        `json:"Success"`
+	Failure []ReservationFailure `json:"Failure"`
+}
+
+type LockRequest struct {
+	IDs             []string         `json:"IDs" jsonschema:"description=Xnames of the components to lock"`
+	Owner           string           `json:"Owner"`
+	Scope           ReservationScope `json:"Scope,omitempty"`
+	DurationMinutes int              `json:"DurationMinutes,omitempty" jsonschema:"description=Lifetime of the lock. Zero locks until released."`
+	ProcessingModel ProcessingModel  `json:"ProcessingModel,omitempty"`
+}
+
+type LockReleaseRequest struct {
+	IDs             []string        `json:"IDs" jsonschema:"description=Xnames of the components to unlock"`
+	Owner           string          `json:"Owner"`
+	ProcessingModel ProcessingModel `json:"ProcessingModel,omitempty"`
+}
+
+type LockResponse struct {
+	Success []Lock               `json:"Success"`
+	Failure []ReservationFailure `json:"Failure"`
+}
+
+// ReservationTable tracks the reservations and locks held on a set of
+// components.  It updates the Locked and Flag fields of the components it was
+// created with in place, restoring the previous Flag when a component is
+// unlocked.  Expired reservations and locks are removed at the start of every
+// operation that changes the table.  It is not safe for concurrent use.
+type ReservationTable struct {
+	components   map[string]*Component
+	reservations map[string]Reservation
+	locks        map[string]Lock
+	disabled     map[string]bool          // Xnames with ReservationDisabled set
+	flags        map[string]ComponentFlag // Flags of locked components before they were locked
+}
+
+// NewReservationTable creates a table over components, restoring any
+// previously persisted reservations and locks.
+func NewReservationTable(components []Component, reservations []Reservation, locks []Lock) *ReservationTable {
+	t := &ReservationTable{
+		components:   make(map[string]*Component, len(components)),
+		reservations: make(map[string]Reservation, len(reservations)),
+		locks:        make(map[string]Lock, len(locks)),
+		disabled:     make(map[string]bool),
+		flags:        make(map[string]ComponentFlag),
+	}
+	for i := range components {
+		c := &components[i]
+		t.components[c.ID] = c
+		if c.ReservationDisabled.OrElse(false) {
+			t.disabled[c.ID] = true
+		}
+	}
+	for _, r := range reservations {
+		t.reservations[r.ID] = r
+	}
+	for _, l := range locks {
+		t.locks[l.ID] = l
+		t.updateLocked(l)
+	}
+	return t
+}
+
+// Reservations returns the current reservations ordered by xname.
+func (t *ReservationTable) Reservations() []Reservation {
+	reservations := make([]Reservation, 0, len(t.reservations))
+	for _, r := range t.reservations {
+		reservations = append(reservations, r)
+	}
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].ID < reservations[j].ID })
+	return reservations
+}
+
+// Locks returns the current locks ordered by xname.
+func (t *ReservationTable) Locks() []Lock {
+	locks := make([]Lock, 0, len(t.locks))
+	for _, l := range t.locks {
+		locks = append(locks, l)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].ID < locks[j].ID })
+	return locks
+}
+
+// Expire removes the reservations and locks that have expired by now.
+func (t *ReservationTable) Expire(now time.Time) {
+	for id, r := range t.reservations {
+		if expired(r.ExpirationTime, now) {
+			delete(t.reservations, id)
+		}
+	}
+	for id, l := range t.locks {
+		if expired(l.ExpirationTime, now) {
+			delete(t.locks, id)
+			t.updateLocked(l)
+		}
+	}
+}
+
+// SetReservationDisabled sets ReservationDisabled on a component.  Disabling
+// reservations also removes any reservation currently held on it.
+func (t *ReservationTable) SetReservationDisabled(id string, disabled bool) error {
+	c, ok := t.components[id]
+	if !ok {
+		return fmt.Errorf("component %s not found", id)
+	}
+	c.ReservationDisabled = schemas.Some(disabled)
+	if disabled {
+		t.disabled[id] = true
+		delete(t.reservations, id)
+	} else {
+		delete(t.disabled, id)
+	}
+	return nil
+}
+
+// Reserve creates reservations for the requested components.
+func (t *ReservationTable) Reserve(req ReservationRequest, now time.Time) (ReservationResponse, error) {
+	t.Expire(now)
+	var resp ReservationResponse
+	if req.Owner == "" {
+		return resp, fmt.Errorf("reservation owner is required")
+	}
+	if req.DurationMinutes < 1 {
+		return resp, fmt.Errorf("reservation duration must be at least one minute")
+	}
+	if err := validateScopeAndModel(req.Scope, req.ProcessingModel); err != nil {
+		return resp, err
+	}
+
+	var pending []Reservation
+	for _, id := range req.IDs {
+		r := Reservation{
+			ID:             id,
+			Owner:          req.Owner,
+			Scope:          req.Scope,
+			ReservationKey: uuid.NewString(),
+			DepositionKey:  uuid.NewString(),
+			Created:        now,
+			ExpirationTime: now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+		}
+		if reason := t.reserveConflict(r, pending); reason != "" {
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: id, Reason: reason})
+			continue
+		}
+		pending = append(pending, r)
+	}
+	if len(resp.Failure) > 0 && req.ProcessingModel != ProcessingFlexible {
+		return ReservationResponse{Failure: resp.Failure}, nil
+	}
+	for _, r := range pending {
+		t.reservations[r.ID] = r
+	}
+	resp.Success = pending
+	return resp, nil
+}
+
+// Renew extends the reservations identified by their deposition keys.
+func (t *ReservationTable) Renew(req ReservationRenewRequest, now time.Time) (ReservationResponse, error) {
+	t.Expire(now)
+	var resp ReservationResponse
+	if req.DurationMinutes < 1 {
+		return resp, fmt.Errorf("reservation duration must be at least one minute")
+	}
+	if err := req.ProcessingModel.Valid(); err != nil {
+		return resp, err
+	}
+
+	var pending []Reservation
+	for _, key := range req.Keys {
+		r, reason := t.heldReservation(key, now)
+		if reason == "" {
+			if l, locked := t.lockCovering(r.ID, r.Scope); locked {
+				reason = fmt.Sprintf("component is locked by %s", l.Owner)
+			}
+		}
+		if reason != "" {
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: key.ID, Reason: reason})
+			continue
+		}
+		r.ExpirationTime = now.Add(time.Duration(req.DurationMinutes) * time.Minute)
+		pending = append(pending, r)
+	}
+	if len(resp.Failure) > 0 && req.ProcessingModel != ProcessingFlexible {
+		return ReservationResponse{Failure: resp.Failure}, nil
+	}
+	for _, r := range pending {
+		t.reservations[r.ID] = r
+	}
+	resp.Success = pending
+	return resp, nil
+}
+
+// Release removes the reservations identified by their deposition keys.
+func (t *ReservationTable) Release(req ReservationReleaseRequest, now time.Time) (ReservationResponse, error) {
+	t.Expire(now)
+	var resp ReservationResponse
+	if err := req.ProcessingModel.Valid(); err != nil {
+		return resp, err
+	}
+
+	var pending []Reservation
+	for _, key := range req.Keys {
+		r, reason := t.heldReservation(key, now)
+		if reason != "" {
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: key.ID, Reason: reason})
+			continue
+		}
+		pending = append(pending, r)
+	}
+	if len(resp.Failure) > 0 && req.ProcessingModel != ProcessingFlexible {
+		return ReservationResponse{Failure: resp.Failure}, nil
+	}
+	for _, r := range pending {
+		delete(t.reservations, r.ID)
+	}
+	resp.Success = pending
+	return resp, nil
+}
+
+// Check verifies reservation keys that another service has presented.  The
+// returned reservations have their deposition keys removed.
+func (t *ReservationTable) Check(keys []ReservationKey, now time.Time) ReservationResponse {
+	var resp ReservationResponse
+	for _, key := range keys {
+		r, ok := t.reservations[key.ID]
+		switch {
+		case !ok || expired(r.ExpirationTime, now):
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: key.ID, Reason: "component is not reserved"})
+		case r.ReservationKey != key.Key:
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: key.ID, Reason: "invalid reservation key"})
+		default:
+			r.DepositionKey = ""
+			resp.Success = append(resp.Success, r)
+		}
+	}
+	return resp
+}
+
+// Lock locks the requested components.  Existing reservations are kept, but
+// cannot be renewed and no new ones can be made until the lock is released.
+func (t *ReservationTable) Lock(req LockRequest, now time.Time) (LockResponse, error) {
+	t.Expire(now)
+	var resp LockResponse
+	if req.Owner == "" {
+		return resp, fmt.Errorf("lock owner is required")
+	}
+	if req.DurationMinutes < 0 {
+		return resp, fmt.Errorf("lock duration cannot be negative")
+	}
+	if err := validateScopeAndModel(req.Scope, req.ProcessingModel); err != nil {
+		return resp, err
+	}
+
+	var pending []Lock
+	for _, id := range req.IDs {
+		l := Lock{ID: id, Owner: req.Owner, Scope: req.Scope, Created: now}
+		if req.DurationMinutes > 0 {
+			l.ExpirationTime = now.Add(time.Duration(req.DurationMinutes) * time.Minute)
+		}
+		reason := ""
+		if _, ok := t.components[id]; !ok {
+			reason = "component not found"
+		} else if existing, locked := t.lockCovering(id, req.Scope); locked {
+			reason = fmt.Sprintf("component is already locked by %s", existing.Owner)
+		} else {
+			for _, p := range pending {
+				if overlaps(p.ID, p.Scope, id, req.Scope) {
+					reason = fmt.Sprintf("component overlaps %s in the same request", p.ID)
+					break
+				}
+			}
+		}
+		if reason != "" {
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: id, Reason: reason})
+			continue
+		}
+		pending = append(pending, l)
+	}
+	if len(resp.Failure) > 0 && req.ProcessingModel != ProcessingFlexible {
+		return LockResponse{Failure: resp.Failure}, nil
+	}
+	for _, l := range pending {
+		t.locks[l.ID] = l
+		t.updateLocked(l)
+	}
+	resp.Success = pending
+	return resp, nil
+}
+
+// Unlock releases locks held by the requesting owner.
+func (t *ReservationTable) Unlock(req LockReleaseRequest, now time.Time) (LockResponse, error) {
+	t.Expire(now)
+	var resp LockResponse
+	if err := req.ProcessingModel.Valid(); err != nil {
+		return resp, err
+	}
+
+	var pending []Lock
+	for _, id := range req.IDs {
+		l, ok := t.locks[id]
+		switch {
+		case !ok || expired(l.ExpirationTime, now):
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: id, Reason: "component is not locked"})
+		case l.Owner != req.Owner:
+			resp.Failure = append(resp.Failure, ReservationFailure{ID: id, Reason: fmt.Sprintf("component is locked by %s", l.Owner)})
+		default:
+			pending = append(pending, l)
+		}
+	}
+	if len(resp.Failure) > 0 && req.ProcessingModel != ProcessingFlexible {
+		return LockResponse{Failure: resp.Failure}, nil
+	}
+	for _, l := range pending {
+		delete(t.locks, l.ID)
+		t.updateLocked(l)
+	}
+	resp.Success = pending
+	return resp, nil
+}
+
+// reserveConflict returns the reason r cannot be granted, or "" if it can.
+func (t *ReservationTable) reserveConflict(r Reservation, pending []Reservation) string {
+	if _, ok := t.components[r.ID]; !ok {
+		return "component not found"
+	}
+	if t.disabled[r.ID] {
+		return fmt.Sprintf("reservations are disabled on %s", r.ID)
+	}
+	if r.Scope == ScopeSubtree {
+		for id := range t.disabled {
+			if XnameIsAncestor(r.ID, id) {
+				return fmt.Sprintf("reservations are disabled on %s", id)
+			}
+		}
+	}
+	if l, locked := t.lockCovering(r.ID, r.Scope); locked {
+		return fmt.Sprintf("component is locked by %s", l.Owner)
+	}
+	if existing, ok := findOverlap(t.reservations, func(r Reservation) ReservationScope { return r.Scope }, r.ID, r.Scope); ok {
+		return fmt.Sprintf("component is already reserved by %s", existing.Owner)
+	}
+	for _, p := range pending {
+		if overlaps(p.ID, p.Scope, r.ID, r.Scope) {
+			return fmt.Sprintf("component overlaps %s in the same request", p.ID)
+		}
+	}
+	return ""
+}
+
+// heldReservation returns the unexpired reservation matching a deposition key.
+func (t *ReservationTable) heldReservation(key ReservationKey, now time.Time) (Reservation, string) {
+	r, ok := t.reservations[key.ID]
+	if !ok || expired(r.ExpirationTime, now) {
+		return r, "component is not reserved"
+	}
+	if r.DepositionKey != key.Key {
+		return r, "invalid deposition key"
+	}
+	return r, ""
+}
+
+// lockCovering returns a lock that overlaps the given xname and scope.
+func (t *ReservationTable) lockCovering(id string, scope ReservationScope) (Lock, bool) {
+	return findOverlap(t.locks, func(l Lock) ReservationScope { return l.Scope }, id, scope)
+}
+
+// findOverlap returns an entry of m, which is keyed by xname, that overlaps
+// the given xname and scope.  Entries on the xname and its ancestors are
+// looked up directly, so m is only scanned for the descendants of a Subtree.
+func findOverlap[T any](m map[string]T, scopeOf func(T) ReservationScope, id string, scope ReservationScope) (T, bool) {
+	if v, ok := m[id]; ok {
+		return v, true
+	}
+	for a := XnameParent(id); a != ""; a = XnameParent(a) {
+		if v, ok := m[a]; ok && scopeOf(v) == ScopeSubtree {
+			return v, true
+		}
+	}
+	if scope == ScopeSubtree {
+		for x, v := range m {
+			if XnameIsAncestor(id, x) {
+				return v, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// updateLocked recomputes Component.Locked for every component covered by l
+// from the locks now in the table, so that removing one lock leaves
+// components covered by another locked.  Components that become locked have
+// their Flag saved and set to Locked, and it is restored when they are
+// unlocked unless something else has changed it in the meantime.
+func (t *ReservationTable) updateLocked(l Lock) {
+	for _, c := range t.covered(l.ID, l.Scope) {
+		_, locked := t.lockCovering(c.ID, ScopeComponent)
+		wasLocked := c.Locked.OrElse(false)
+		switch {
+		case locked && !wasLocked:
+			if c.Flag != FlagLocked {
+				t.flags[c.ID] = c.Flag
+			}
+			c.Flag = FlagLocked
+		case !locked && wasLocked:
+			if c.Flag == FlagLocked {
+				c.Flag = FlagOK
+				if flag, ok := t.flags[c.ID]; ok {
+					c.Flag = flag
+				}
+			}
+			delete(t.flags, c.ID)
+		}
+		c.Locked = schemas.Some(locked)
+	}
+}
+
+// covered returns the components in the table covered by an xname and scope.
+func (t *ReservationTable) covered(id string, scope ReservationScope) []*Component {
+	var components []*Component
+	if c, ok := t.components[id]; ok {
+		components = append(components, c)
+	}
+	if scope == ScopeSubtree {
+		for x, c := range t.components {
+			if XnameIsAncestor(id, x) {
+				components = append(components, c)
+			}
+		}
+	}
+	return components
+}
+
+func validateScopeAndModel(scope ReservationScope, model ProcessingModel) error {
+	if err := scope.Valid(); err != nil {
+		return err
+	}
+	if err := model.Valid(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// overlaps reports whether the components covered by two xname/scope pairs intersect.
+func overlaps(a string, aScope ReservationScope, b string, bScope ReservationScope) bool {
+	return a == b ||
+		(aScope == ScopeSubtree && XnameIsAncestor(a, b)) ||
+		(bScope == ScopeSubtree && XnameIsAncestor(b, a))
+}
+
+// expired reports whether an expiration time has passed.  A zero time never expires.
+func expired(expiration, now time.Time) bool {
+	return !expiration.IsZero() && !now.Before(expiration)
+}
@@ -0,0 +1,254 @@
+package csm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+var reservationStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func reservationComponents() []Component {
+	return []Component{
+		{ID: "x1000c0s0b0n0", Type: "Node", State: StateReady, Flag: FlagOK},
+		{ID: "x1000c0s0b0n1", Type: "Node", State: StateReady, Flag: FlagWarning},
+		{ID: "x1000c0s1b0n0", Type: "Node", State: StateReady, Flag: FlagOK, ReservationDisabled: schemas.Some(true)},
+		{ID: "x1000c1s0b0n0", Type: "Node", State: StateReady, Flag: FlagOK},
+		{ID: "x1000c0", Type: "Chassis", State: StateOn, Flag: FlagOK},
+		{ID: "x1000c0s0b0", Type: "NodeBMC", State: StateReady, Flag: FlagOK},
+	}
+}
+
+func TestReservationAcquireRenewRelease(t *testing.T) {
+	table := NewReservationTable(reservationComponents(), nil, nil)
+	resp, err := table.Reserve(ReservationRequest{IDs: []string{"x1000c0s0b0n0"}, Owner: "fw", DurationMinutes: 10}, reservationStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Success) != 1 || len(resp.Failure) != 0 {
+		t.Fatalf("Reserve() = %+v, want one success", resp)
+	}
+	r := resp.Success[0]
+	deposition := ReservationKey{ID: r.ID, Key: r.DepositionKey}
+
+	check := table.Check([]ReservationKey{{ID: r.ID, Key: r.ReservationKey}}, reservationStart)
+	if len(check.Success) != 1 || check.Success[0].DepositionKey != "" {
+		t.Errorf("Check() = %+v, want the reservation without its deposition key", check)
+	}
+	if check := table.Check([]ReservationKey{{ID: r.ID, Key: "wrong"}}, reservationStart); len(check.Failure) != 1 {
+		t.Errorf("Check() with a wrong key = %+v, want a failure", check)
+	}
+
+	later := reservationStart.Add(9 * time.Minute)
+	resp, err = table.Renew(ReservationRenewRequest{Keys: []ReservationKey{deposition}, DurationMinutes: 10}, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Success) != 1 || !resp.Success[0].ExpirationTime.Equal(later.Add(10*time.Minute)) {
+		t.Fatalf("Renew() = %+v, want expiration 10 minutes after renewal", resp)
+	}
+	if check := table.Check([]ReservationKey{{ID: r.ID, Key: r.ReservationKey}}, reservationStart.Add(15*time.Minute)); len(check.Success) != 1 {
+		t.Errorf("renewed reservation expired: %+v", check)
+	}
+
+	if resp, _ := table.Release(ReservationReleaseRequest{Keys: []ReservationKey{{ID: r.ID, Key: r.ReservationKey}}}, later); len(resp.Failure) != 1 {
+		t.Errorf("Release() with the reservation key = %+v, want a failure", resp)
+	}
+	if resp, _ := table.Release(ReservationReleaseRequest{Keys: []ReservationKey{deposition}}, later); len(resp.Success) != 1 {
+		t.Fatalf("Release() = %+v, want one success", resp)
+	}
+	if resp, _ := table.Reserve(ReservationRequest{IDs: []string{"x1000c0s0b0n0"}, Owner: "other", DurationMinutes: 1}, later); len(resp.Success) != 1 {
+		t.Errorf("Reserve() after release = %+v, want one success", resp)
+	}
+}
+
+func TestReservationExpires(t *testing.T) {
+	table := NewReservationTable(reservationComponents(), nil, nil)
+	resp, _ := table.Reserve(ReservationRequest{IDs: []string{"x1000c0s0b0n0"}, Owner: "fw", DurationMinutes: 1}, reservationStart)
+	r := resp.Success[0]
+
+	later := reservationStart.Add(time.Minute)
+	if resp, _ := table.Renew(ReservationRenewRequest{Keys: []ReservationKey{{ID: r.ID, Key: r.DepositionKey}}, DurationMinutes: 1}, later); len(resp.Failure) != 1 {
+		t.Errorf("Renew() of an expired reservation = %+v, want a failure", resp)
+	}
+	if resp, _ := table.Reserve(ReservationRequest{IDs: []string{"x1000c0"}, Owner: "other", Scope: ScopeSubtree, DurationMinutes: 1, ProcessingModel: ProcessingFlexible}, later); len(resp.Failure) != 1 || resp.Failure[0].ID != "x1000c0" {
+		t.Errorf("Reserve() of x1000c0 = %+v, want only the disabled component to conflict", resp)
+	}
+}
+
+func TestReservationConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*ReservationTable)
+		req   ReservationRequest
+		ok    []string
+	}{
+		{
+			name: "not found",
+			req:  ReservationRequest{IDs: []string{"x9000c0s0b0n0", "x1000c0s0b0n0"}},
+			ok:   []string{"x1000c0s0b0n0"},
+		},
+		{
+			name: "reservations disabled",
+			req:  ReservationRequest{IDs: []string{"x1000c0s1b0n0", "x1000c0s0b0n0"}},
+			ok:   []string{"x1000c0s0b0n0"},
+		},
+		{
+			name: "disabled beneath a subtree",
+			req:  ReservationRequest{IDs: []string{"x1000c0", "x1000c1s0b0n0"}, Scope: ScopeSubtree},
+			ok:   []string{"x1000c1s0b0n0"},
+		},
+		{
+			name: "already reserved",
+			setup: func(table *ReservationTable) {
+				table.Reserve(ReservationRequest{IDs: []string{"x1000c0s0b0n0"}, Owner: "fw", DurationMinutes: 10}, reservationStart)
+			},
+			req: ReservationRequest{IDs: []string{"x1000c0s0b0n0", "x1000c0s0b0n1"}},
+			ok:  []string{"x1000c0s0b0n1"},
+		},
+		{
+			name: "reserved beneath a subtree",
+			setup: func(table *ReservationTable) {
+				table.Reserve(ReservationRequest{IDs: []string{"x1000c0s0b0n1"}, Owner: "fw", DurationMinutes: 10}, reservationStart)
+			},
+			req: ReservationRequest{IDs: []string{"x1000c0s0b0", "x1000c1s0b0n0"}, Scope: ScopeSubtree},
+			ok:  []string{"x1000c1s0b0n0"},
+		},
+		{
+			name: "locked ancestor",
+			setup: func(table *ReservationTable) {
+				table.Lock(LockRequest{IDs: []string{"x1000c0s0b0"}, Owner: "admin", Scope: ScopeSubtree}, reservationStart)
+			},
+			req: ReservationRequest{IDs: []string{"x1000c0s0b0n0", "x1000c1s0b0n0"}},
+			ok:  []string{"x1000c1s0b0n0"},
+		},
+		{
+			name: "overlap in the same request",
+			req:  ReservationRequest{IDs: []string{"x1000c0s0b0", "x1000c0s0b0n0"}, Scope: ScopeSubtree},
+			ok:   []string{"x1000c0s0b0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewReservationTable(reservationComponents(), nil, nil)
+			if tt.setup != nil {
+				tt.setup(table)
+			}
+			req := tt.req
+			req.Owner, req.DurationMinutes = "test", 10
+
+			// Rigid requests are all or nothing.
+			resp, err := table.Reserve(req, reservationStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Success) != 0 || len(resp.Failure) != len(req.IDs)-len(tt.ok) {
+				t.Fatalf("rigid Reserve() = %+v, want %d failures and no successes", resp, len(req.IDs)-len(tt.ok))
+			}
+
+			req.ProcessingModel = ProcessingFlexible
+			resp, err = table.Reserve(req, reservationStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range resp.Success {
+				got = append(got, r.ID)
+			}
+			if len(got) != len(tt.ok) || (len(got) > 0 && got[0] != tt.ok[0]) {
+				t.Errorf("flexible Reserve() granted %v, want %v (failures %+v)", got, tt.ok, resp.Failure)
+			}
+		})
+	}
+}
+
+func TestReservationLock(t *testing.T) {
+	components := reservationComponents()
+	table := NewReservationTable(components, nil, nil)
+	resp, _ := table.Reserve(ReservationRequest{IDs: []string{"x1000c0s0b0n1"}, Owner: "fw", DurationMinutes: 10}, reservationStart)
+	r := resp.Success[0]
+
+	lock, err := table.Lock(LockRequest{IDs: []string{"x1000c0s0b0"}, Owner: "admin", Scope: ScopeSubtree}, reservationStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Success) != 1 {
+		t.Fatalf("Lock() = %+v, want one success", lock)
+	}
+	for _, c := range components[:2] {
+		if !c.Locked.OrElse(false) || c.Flag != FlagLocked {
+			t.Errorf("%s Locked %v Flag %s, want locked", c.ID, c.Locked, c.Flag)
+		}
+	}
+	if components[2].Locked.OrElse(false) || components[2].Flag != FlagOK {
+		t.Errorf("%s was locked outside the subtree", components[2].ID)
+	}
+	if resp, _ := table.Renew(ReservationRenewRequest{Keys: []ReservationKey{{ID: r.ID, Key: r.DepositionKey}}, DurationMinutes: 10}, reservationStart); len(resp.Failure) != 1 {
+		t.Errorf("Renew() under a lock = %+v, want a failure", resp)
+	}
+	if resp, _ := table.Unlock(LockReleaseRequest{IDs: []string{"x1000c0s0b0"}, Owner: "someone else"}, reservationStart); len(resp.Failure) != 1 {
+		t.Errorf("Unlock() by another owner = %+v, want a failure", resp)
+	}
+
+	if resp, _ := table.Unlock(LockReleaseRequest{IDs: []string{"x1000c0s0b0"}, Owner: "admin"}, reservationStart); len(resp.Success) != 1 {
+		t.Fatalf("Unlock() = %+v, want one success", resp)
+	}
+	if components[0].Locked.OrElse(false) || components[0].Flag != FlagOK || components[1].Flag != FlagWarning {
+		t.Errorf("unlock left %+v and %+v, want the original flags restored", components[0], components[1])
+	}
+}
+
+func TestExpiredLockDoesNotUnlockNewLock(t *testing.T) {
+	components := reservationComponents()
+	stale := Lock{ID: "x1000c0s0b0", Owner: "old", Scope: ScopeSubtree, Created: reservationStart, ExpirationTime: reservationStart.Add(time.Minute)}
+	table := NewReservationTable(components, nil, []Lock{stale})
+
+	later := reservationStart.Add(2 * time.Minute)
+	if resp, _ := table.Lock(LockRequest{IDs: []string{"x1000c0s0b0n0"}, Owner: "new"}, later); len(resp.Success) != 1 {
+		t.Fatalf("Lock() over an expired lock = %+v, want one success", resp)
+	}
+	table.Expire(later.Add(time.Hour))
+	if !components[0].Locked.OrElse(false) || components[0].Flag != FlagLocked {
+		t.Errorf("%s Locked %v Flag %s, want it still held by the new lock", components[0].ID, components[0].Locked, components[0].Flag)
+	}
+	if components[1].Locked.OrElse(false) {
+		t.Errorf("%s is still locked by the expired lock", components[1].ID)
+	}
+}
+
+func TestOverlappingLocksKeepComponentLocked(t *testing.T) {
+	components := reservationComponents()
+	table := NewReservationTable(components, nil, []Lock{
+		{ID: "x1000c0", Owner: "admin", Scope: ScopeSubtree, ExpirationTime: reservationStart.Add(time.Minute)},
+		{ID: "x1000c0s0b0n0", Owner: "admin", Scope: ScopeComponent},
+	})
+	table.Expire(reservationStart.Add(time.Minute))
+	if !components[0].Locked.OrElse(false) {
+		t.Errorf("%s was unlocked while another lock still covers it", components[0].ID)
+	}
+	if components[1].Locked.OrElse(false) || components[1].Flag != FlagWarning {
+		t.Errorf("%s Locked %v Flag %s, want unlocked with its flag restored", components[1].ID, components[1].Locked, components[1].Flag)
+	}
+}
+
+func TestReservationEnumValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid func() error
+		ok    bool
+	}{
+		{"empty scope defaults", ReservationScope("").Valid, true},
+		{"known scope", ScopeSubtree.Valid, true},
+		{"unknown scope", ReservationScope("Everything").Valid, false},
+		{"empty processing model defaults", ProcessingModel("").Valid, true},
+		{"unknown processing model", ProcessingModel("Lenient").Valid, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.valid(); (err == nil) != tt.ok {
+				t.Errorf("Valid() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package csm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPinMismatch is returned, wrapped, when an endpoint presents a
+// certificate other than the one pinned in its TLSInfo.
+var ErrPinMismatch = errors.New("certificate does not match pinned fingerprint")
+
+// TLSInfo describes the certificate and connection parameters an endpoint
+// presented when it was last contacted.
+type TLSInfo struct {
+	Fingerprint   string    `json:"Fingerprint,omitempty" jsonschema:"description=Hex encoded SHA-256 digest of the DER leaf certificate"`
+	Issuer        string    `json:"Issuer,omitempty"`
+	Subject       string    `json:"Subject,omitempty"`
+	SANs          []string  `json:"SANs,omitempty" jsonschema:"description=DNS names and IP addresses in the certificate's subject alternative names"`
+	NotBefore     time.Time `json:"NotBefore,omitempty" jsonschema:"format=date-time"`
+	NotAfter      time.Time `json:"NotAfter,omitempty" jsonschema:"format=date-time"`
+	Version       string    `json:"Version,omitempty" jsonschema:"description=Negotiated TLS version e.g. TLS 1.3"`
+	CipherSuite   string    `json:"CipherSuite,omitempty" jsonschema:"description=Negotiated cipher suite"`
+	SelfSigned    bool      `json:"SelfSigned,omitempty"`
+	VerifiedChain bool      `json:"VerifiedChain,omitempty" jsonschema:"description=Whether the certificate chain verified against the trusted roots"`
+	Pinned        bool      `json:"Pinned,omitempty" jsonschema:"description=Require subsequent connections to present the certificate with this Fingerprint"`
+	ObservedAt    time.Time `json:"ObservedAt,omitempty" jsonschema:"description=Time the certificate was observed,format=date-time"`
+}
+
+// NewTLSInfo fills a TLSInfo from the state of an established connection.
+func NewTLSInfo(cs tls.ConnectionState, now time.Time) TLSInfo {
+	info := TLSInfo{
+		Version:       tls.VersionName(cs.Version),
+		CipherSuite:   tls.CipherSuiteName(cs.CipherSuite),
+		VerifiedChain: len(cs.VerifiedChains) > 0,
+		ObservedAt:    now,
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return info
+	}
+	leaf := cs.PeerCertificates[0]
+	info.Fingerprint = CertificateFingerprint(leaf)
+	info.Issuer = leaf.Issuer.String()
+	info.Subject = leaf.Subject.String()
+	info.NotBefore = leaf.NotBefore
+	info.NotAfter = leaf.NotAfter
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	// CheckSignatureFrom would require the certificate to be a CA, which many
+	// BMC certificates are not.
+	info.SelfSigned = bytes.Equal(leaf.RawIssuer, leaf.RawSubject) &&
+		leaf.CheckSignature(leaf.SignatureAlgorithm, leaf.RawTBSCertificate, leaf.Signature) == nil
+	return info
+}
+
+// CertificateFingerprint returns the hex encoded SHA-256 digest of a certificate.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ExpiresWithin reports whether the certificate expires within d of now.
+func (t TLSInfo) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return !t.NotAfter.IsZero() && now.Add(d).After(t.NotAfter)
+}
+
+// VerifyPinned checks a connection against the pinned fingerprint.  It always
+// succeeds when the certificate is not pinned.
+func (t TLSInfo) VerifyPinned(cs tls.ConnectionState) error {
+	if !t.Pinned {
+		return nil
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("no certificate presented: %w %s", ErrPinMismatch, t.Fingerprint)
+	}
+	if got := CertificateFingerprint(cs.PeerCertificates[0]); got != t.Fingerprint {
+		return fmt.Errorf("fingerprint %s: %w %s", got, ErrPinMismatch, t.Fingerprint)
+	}
+	return nil
+}
+
+// PinnedConfig returns a copy of base that accepts only the pinned
+// certificate, even if it is self-signed.  The check runs during the
+// handshake, so nothing is sent to an endpoint that fails it.  If the
+// certificate is not pinned, base is returned unchanged.
+func (t TLSInfo) PinnedConfig(base *tls.Config) *tls.Config {
+	if !t.Pinned {
+		return base
+	}
+	var config *tls.Config
+	if base == nil {
+		config = &tls.Config{}
+	} else {
+		config = base.Clone()
+	}
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = t.VerifyPinned
+	return config
+}
+
+// ObserveTLS records the TLS state of a connection to the endpoint.  If the
+// endpoint's certificate is pinned and the connection presented a different
+// one, an error is returned and TLSInfo is left unchanged.
+func (ep *RedfishEndpoint) ObserveTLS(cs tls.ConnectionState, now time.Time) error {
+	info := NewTLSInfo(cs, now)
+	if ep.TLSInfo != nil && ep.TLSInfo.Pinned {
+		if err := ep.TLSInfo.VerifyPinned(cs); err != nil {
+			return fmt.Errorf("endpoint %s: %w", ep.ID, err)
+		}
+		info.Pinned = true
+	}
+	ep.TLSInfo = &info
+	return nil
+}
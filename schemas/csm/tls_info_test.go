@@ -0,0 +1,162 @@
+package csm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+var tlsNow = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// selfSigned creates a self-signed certificate for name valid for a year from
+// tlsNow.
+func selfSigned(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("10.254.1.12")},
+		NotBefore:    tlsNow,
+		NotAfter:     tlsNow.AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func connectionState(certs ...*x509.Certificate) tls.ConnectionState {
+	return tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256, PeerCertificates: certs}
+}
+
+func TestNewTLSInfo(t *testing.T) {
+	cert := selfSigned(t, "x1000c0s0b0")
+	info := NewTLSInfo(connectionState(cert), tlsNow)
+	if info.Fingerprint != CertificateFingerprint(cert) || len(info.Fingerprint) != 64 {
+		t.Errorf("Fingerprint = %q", info.Fingerprint)
+	}
+	if info.Version != "TLS 1.3" || info.CipherSuite != "TLS_AES_128_GCM_SHA256" {
+		t.Errorf("Version %q CipherSuite %q", info.Version, info.CipherSuite)
+	}
+	if info.Subject != "CN=x1000c0s0b0" || !info.SelfSigned || info.VerifiedChain {
+		t.Errorf("Subject %q SelfSigned %v VerifiedChain %v", info.Subject, info.SelfSigned, info.VerifiedChain)
+	}
+	if len(info.SANs) != 2 || info.SANs[0] != "x1000c0s0b0" || info.SANs[1] != "10.254.1.12" {
+		t.Errorf("SANs = %v", info.SANs)
+	}
+	if !info.ObservedAt.Equal(tlsNow) || !info.NotAfter.Equal(cert.NotAfter) {
+		t.Errorf("ObservedAt %v NotAfter %v", info.ObservedAt, info.NotAfter)
+	}
+
+	if info := NewTLSInfo(connectionState(), tlsNow); info.Fingerprint != "" || info.Version != "TLS 1.3" {
+		t.Errorf("NewTLSInfo() without certificates = %+v", info)
+	}
+}
+
+func TestTLSInfoExpiresWithin(t *testing.T) {
+	info := TLSInfo{NotAfter: tlsNow.AddDate(0, 0, 30)}
+	tests := []struct {
+		d    time.Duration
+		want bool
+	}{
+		{7 * 24 * time.Hour, false},
+		{31 * 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		if got := info.ExpiresWithin(tt.d, tlsNow); got != tt.want {
+			t.Errorf("ExpiresWithin(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+	if (TLSInfo{}).ExpiresWithin(time.Hour, tlsNow) {
+		t.Error("ExpiresWithin() without NotAfter is true")
+	}
+}
+
+func TestTLSInfoVerifyPinned(t *testing.T) {
+	pinned, other := selfSigned(t, "x1000c0s0b0"), selfSigned(t, "x1000c0s0b0")
+	info := TLSInfo{Fingerprint: CertificateFingerprint(pinned), Pinned: true}
+	tests := []struct {
+		name string
+		info TLSInfo
+		cs   tls.ConnectionState
+		ok   bool
+	}{
+		{"pinned certificate", info, connectionState(pinned), true},
+		{"other certificate", info, connectionState(other), false},
+		{"no certificate", info, connectionState(), false},
+		{"not pinned", TLSInfo{Fingerprint: info.Fingerprint}, connectionState(other), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.info.VerifyPinned(tt.cs)
+			if (err == nil) != tt.ok || (err != nil && !errors.Is(err, ErrPinMismatch)) {
+				t.Errorf("VerifyPinned() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTLSInfoPinnedConfig(t *testing.T) {
+	base := &tls.Config{ServerName: "x1000c0s0b0", MinVersion: tls.VersionTLS12}
+	if got := (TLSInfo{}).PinnedConfig(base); got != base {
+		t.Error("PinnedConfig() of an unpinned certificate did not return base")
+	}
+
+	info := TLSInfo{Fingerprint: "00", Pinned: true}
+	config := info.PinnedConfig(base)
+	if config == base || base.InsecureSkipVerify || base.VerifyConnection != nil {
+		t.Error("PinnedConfig() modified base")
+	}
+	if !config.InsecureSkipVerify || config.VerifyConnection == nil || config.ServerName != "x1000c0s0b0" || config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("PinnedConfig() = %+v", config)
+	}
+	if err := config.VerifyConnection(connectionState(selfSigned(t, "x1000c0s0b0"))); !errors.Is(err, ErrPinMismatch) {
+		t.Errorf("VerifyConnection() = %v, want a pin mismatch", err)
+	}
+	if config := info.PinnedConfig(nil); config == nil || config.VerifyConnection == nil {
+		t.Errorf("PinnedConfig(nil) = %+v", config)
+	}
+}
+
+func TestObserveTLS(t *testing.T) {
+	first, second := selfSigned(t, "x1000c0s0b0"), selfSigned(t, "x1000c0s0b0")
+
+	ep := RedfishEndpoint{ID: "x1000c0s0b0"}
+	if err := ep.ObserveTLS(connectionState(first), tlsNow); err != nil || ep.TLSInfo == nil || ep.TLSInfo.Pinned {
+		t.Fatalf("ObserveTLS() = %v, TLSInfo %+v", err, ep.TLSInfo)
+	}
+	// An unpinned certificate may change.
+	if err := ep.ObserveTLS(connectionState(second), tlsNow); err != nil || ep.TLSInfo.Fingerprint != CertificateFingerprint(second) {
+		t.Fatalf("ObserveTLS() of a new certificate = %v, TLSInfo %+v", err, ep.TLSInfo)
+	}
+
+	ep.TLSInfo.Pinned = true
+	later := tlsNow.Add(time.Hour)
+	if err := ep.ObserveTLS(connectionState(first), later); !errors.Is(err, ErrPinMismatch) {
+		t.Errorf("ObserveTLS() of another certificate = %v, want a pin mismatch", err)
+	}
+	if ep.TLSInfo.Fingerprint != CertificateFingerprint(second) || !ep.TLSInfo.ObservedAt.Equal(tlsNow) {
+		t.Errorf("TLSInfo changed after a mismatch: %+v", ep.TLSInfo)
+	}
+	if err := ep.ObserveTLS(connectionState(second), later); err != nil || !ep.TLSInfo.Pinned || !ep.TLSInfo.ObservedAt.Equal(later) {
+		t.Errorf("ObserveTLS() of the pinned certificate = %v, TLSInfo %+v", err, ep.TLSInfo)
+	}
+}
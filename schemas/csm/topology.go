@@ -0,0 +1,127 @@
+package csm
+
+import "sort"
+
+// TopologyNode is a location in the hardware hierarchy, e.g. a cabinet,
+// chassis, slot, BMC or node.
+type TopologyNode struct {
+	ID          string                 `json:"ID" jsonschema:"description=Xname"`
+	Type        ComponentType          `json:"Type"`
+	Synthesized bool                   `json:"Synthesized,omitempty" jsonschema:"description=True when the node fills a gap in the hierarchy and has no matching component"`
+	Component   *Component             `json:"Component,omitempty" jsonschema:"description=The component at this location"`
+	StateCounts map[ComponentState]int `json:"StateCounts,omitempty" jsonschema:"description=Number of components in each State in this subtree including this node"`
+	FlagCounts  map[ComponentFlag]int  `json:"FlagCounts,omitempty" jsonschema:"description=Number of components with each Flag in this subtree including this node"`
+	Children    []*TopologyNode        `json:"Children,omitempty"`
+}
+
+// Topology is a cabinet -> chassis -> slot -> BMC -> node tree assembled from
+// a flat list of components by xname.
+type Topology struct {
+	Roots    []*TopologyNode `json:"Roots"`
+	Unplaced []string        `json:"Unplaced,omitempty" jsonschema:"description=IDs of components that could not be placed because their xname is invalid or duplicated"`
+}
+
+// BuildTopology assembles components into a tree using xname parent
+// relationships.  Missing intermediate locations are synthesized with the type
+// implied by their xname, and State/Flag counts are aggregated per subtree.
+func BuildTopology(components []Component) *Topology {
+	t := &Topology{}
+	nodes := make(map[string]*TopologyNode)
+
+	var ensure func(id string) *TopologyNode
+	ensure = func(id string) *TopologyNode {
+		if n, ok := nodes[id]; ok {
+			return n
+		}
+		n := &TopologyNode{ID: id, Type: XnameType(id), Synthesized: true}
+		nodes[id] = n
+		if parent := XnameParent(id); parent != "" {
+			p := ensure(parent)
+			p.Children = append(p.Children, n)
+		} else {
+			t.Roots = append(t.Roots, n)
+		}
+		return n
+	}
+
+	for i := range components {
+		c := components[i]
+		if XnameSegments(c.ID) == nil {
+			t.Unplaced = append(t.Unplaced, c.ID)
+			continue
+		}
+		n := ensure(c.ID)
+		if n.Component != nil {
+			t.Unplaced = append(t.Unplaced, c.ID)
+			continue
+		}
+		n.Component = &c
+		n.Synthesized = false
+		if c.Type != "" {
+			n.Type = c.Type
+		}
+	}
+
+	sortTopologyNodes(t.Roots)
+	for _, r := range t.Roots {
+		r.aggregate()
+	}
+	return t
+}
+
+func sortTopologyNodes(nodes []*TopologyNode) {
+	sort.Slice(nodes, func(i, j int) bool { return XnameLess(nodes[i].ID, nodes[j].ID) })
+	for _, n := range nodes {
+		sortTopologyNodes(n.Children)
+	}
+}
+
+// aggregate fills in the State and Flag counts for the subtree rooted at n.
+func (n *TopologyNode) aggregate() {
+	n.StateCounts = make(map[ComponentState]int)
+	n.FlagCounts = make(map[ComponentFlag]int)
+	if n.Component != nil {
+		if n.Component.State != "" {
+			n.StateCounts[n.Component.State]++
+		}
+		if n.Component.Flag != "" {
+			n.FlagCounts[n.Component.Flag]++
+		}
+	}
+	for _, c := range n.Children {
+		c.aggregate()
+		for state, count := range c.StateCounts {
+			n.StateCounts[state] += count
+		}
+		for flag, count := range c.FlagCounts {
+			n.FlagCounts[flag] += count
+		}
+	}
+}
+
+// Find returns the node with the given xname, or nil if it is not in the tree.
+func (t *Topology) Find(id string) *TopologyNode {
+	var found *TopologyNode
+	t.Walk(func(n *TopologyNode) bool {
+		if n.ID == id {
+			found = n
+			return false
+		}
+		return XnameIsAncestor(n.ID, id)
+	})
+	return found
+}
+
+// Walk visits the tree depth first.  Returning false from fn skips the
+// children of the current node.
+func (t *Topology) Walk(fn func(*TopologyNode) bool) {
+	var walk func(nodes []*TopologyNode)
+	walk = func(nodes []*TopologyNode) {
+		for _, n := range nodes {
+			if fn(n) {
+				walk(n.Children)
+			}
+		}
+	}
+	walk(t.Roots)
+}
@@ -0,0 +1,102 @@
+package csm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuildTopology(t *testing.T) {
+	components := []Component{
+		{ID: "x1000c0s0b0n1", Type: TypeNode, State: StateOff, Flag: FlagOK},
+		{ID: "x1000c0s0b0n0", Type: TypeNode, State: StateReady, Flag: FlagWarning},
+		{ID: "x1000c0", Type: TypeChassis, State: StateOn, Flag: FlagOK},
+		{ID: "x1000c0s0b0n0", Type: TypeNode, State: StateReady},
+		{ID: "not an xname"},
+		{ID: "x3000c0s10b0n0", Type: TypeNode, State: StateReady, Flag: FlagOK},
+		{ID: "x3000c0s2b0n0", Type: TypeNode, State: StateReady, Flag: FlagOK},
+	}
+	topo := BuildTopology(components)
+
+	if !slices.Equal(topo.Unplaced, []string{"x1000c0s0b0n0", "not an xname"}) {
+		t.Errorf("Unplaced = %v", topo.Unplaced)
+	}
+	var roots []string
+	for _, r := range topo.Roots {
+		roots = append(roots, r.ID)
+	}
+	if !slices.Equal(roots, []string{"x1000", "x3000"}) {
+		t.Errorf("Roots = %v, want [x1000 x3000]", roots)
+	}
+
+	cabinet := topo.Find("x1000")
+	if cabinet == nil || !cabinet.Synthesized || cabinet.Type != TypeCabinet {
+		t.Fatalf("x1000 = %+v, want a synthesized cabinet", cabinet)
+	}
+	if cabinet.StateCounts[StateReady] != 1 || cabinet.StateCounts[StateOff] != 1 || cabinet.StateCounts[StateOn] != 1 {
+		t.Errorf("x1000 StateCounts = %v", cabinet.StateCounts)
+	}
+	if cabinet.FlagCounts[FlagOK] != 2 || cabinet.FlagCounts[FlagWarning] != 1 {
+		t.Errorf("x1000 FlagCounts = %v", cabinet.FlagCounts)
+	}
+
+	chassis := topo.Find("x1000c0")
+	if chassis == nil || chassis.Synthesized || chassis.Component == nil {
+		t.Fatalf("x1000c0 = %+v, want the chassis component", chassis)
+	}
+	if bmc := topo.Find("x1000c0s0b0"); bmc == nil || bmc.Type != TypeNodeBMC || len(bmc.Children) != 2 || bmc.Children[0].ID != "x1000c0s0b0n0" {
+		t.Errorf("x1000c0s0b0 = %+v, want a BMC with n0 and n1", bmc)
+	}
+	if node := topo.Find("x1000c0s0b0n0"); node == nil || node.Component.Flag != FlagWarning {
+		t.Errorf("x1000c0s0b0n0 = %+v, want the first component with that xname", node)
+	}
+
+	// Slots sort numerically.
+	var slots []string
+	for _, s := range topo.Find("x3000c0").Children {
+		slots = append(slots, s.ID)
+	}
+	if !slices.Equal(slots, []string{"x3000c0s2", "x3000c0s10"}) {
+		t.Errorf("x3000c0 children = %v", slots)
+	}
+	if topo.Find("x9000") != nil {
+		t.Error("Find() found a node that is not in the tree")
+	}
+}
+
+func TestXnameType(t *testing.T) {
+	tests := []struct {
+		xname string
+		want  ComponentType
+	}{
+		{"x1000", TypeCabinet},
+		{"x1000c0", TypeChassis},
+		{"x1000c0s0", TypeComputeModule},
+		{"x1000c0s0b0", TypeNodeBMC},
+		{"x1000c0s0b0n0", TypeNode},
+		{"x1000c0r1b0", TypeRouterBMC},
+		{"d0w1", TypeCDUMgmtSwitch},
+		{"x1000q0", TypeINVALID},
+	}
+	for _, tt := range tests {
+		if got := XnameType(tt.xname); got != tt.want {
+			t.Errorf("XnameType(%s) = %s, want %s", tt.xname, got, tt.want)
+		}
+	}
+}
+
+func TestXnameLess(t *testing.T) {
+	xnames := []string{"x1000c10", "x1000c2s0", "x1000c2", "x100", "x1000"}
+	slices.SortFunc(xnames, func(a, b string) int {
+		if XnameLess(a, b) {
+			return -1
+		}
+		if XnameLess(b, a) {
+			return 1
+		}
+		return 0
+	})
+	want := []string{"x100", "x1000", "x1000c2", "x1000c2s0", "x1000c10"}
+	if !slices.Equal(xnames, want) {
+		t.Errorf("sorted %v, want %v", xnames, want)
+	}
+}
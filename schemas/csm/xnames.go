@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/invopop/jsonschema"
 )
@@ -221,3 +222,182 @@ func IsValidBMCXName(xname string) bool {
 
 	return true
 }
+
+var (
+	xnameRegex           = regexp.MustCompile(`^([a-z]+\d+)+$`)
+	xnameSegmentRegex    = regexp.MustCompile(`[a-z]+\d+`)
+	xnameRangeRegex      = regexp.MustCompile(`\[([^\]]*)\]`)
+	xnameRangeBoundRegex = regexp.MustCompile(`^\d{1,6}$`) // At most six digits, so a bound cannot overflow
+)
+
+// maxXnameRangeSize bounds the number of xnames a single range may expand to.
+const maxXnameRangeSize = 65536
+
+// XnameSegments splits an xname into its location segments, e.g.
+// x1000c0s0b0n0 becomes [x1000 c0 s0 b0 n0].  It returns nil if the string is
+// not made up of letter/number segments.
+func XnameSegments(xname string) []string {
+	if !xnameRegex.MatchString(xname) {
+		return nil
+	}
+	return xnameSegmentRegex.FindAllString(xname, -1)
+}
+
+// XnameParent returns the xname of the enclosing component, e.g. the parent of
+// x1000c0s0b0n0 is x1000c0s0b0.  Cabinets and invalid xnames have no parent
+// and return "".
+func XnameParent(xname string) string {
+	segments := XnameSegments(xname)
+	if len(segments) < 2 {
+		return ""
+	}
+	return strings.Join(segments[:len(segments)-1], "")
+}
+
+// XnameIsAncestor reports whether xname is located beneath ancestor.  The
+// comparison is by segment, so x100 is not an ancestor of x1000c0.
+func XnameIsAncestor(ancestor, xname string) bool {
+	a := XnameSegments(ancestor)
+	x := XnameSegments(xname)
+	if len(a) == 0 || len(a) >= len(x) {
+		return false
+	}
+	for i := range a {
+		if a[i] != x[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandXnameRange expands a bracketed xname range such as
+// x1000c0s[0-3,7]b0n[0-1] into the individual xnames it describes.
+func ExpandXnameRange(pattern string) ([]string, error) {
+	loc := xnameRangeRegex.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		if strings.ContainsAny(pattern, "[]") {
+			return nil, fmt.Errorf("unbalanced brackets in xname range %q", pattern)
+		}
+		if XnameSegments(pattern) == nil {
+			return nil, fmt.Errorf("invalid xname %q", pattern)
+		}
+		return []string{pattern}, nil
+	}
+
+	prefix, suffix := pattern[:loc[0]], pattern[loc[1]:]
+	var numbers []int
+	for _, part := range strings.Split(pattern[loc[2]:loc[3]], ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		for _, b := range bounds {
+			if !xnameRangeBoundRegex.MatchString(b) {
+				return nil, fmt.Errorf("invalid range %q in %q", part, pattern)
+			}
+		}
+		start, _ := strconv.Atoi(bounds[0])
+		end := start
+		if len(bounds) == 2 {
+			if end, _ = strconv.Atoi(bounds[1]); end < start {
+				return nil, fmt.Errorf("invalid range %q in %q", part, pattern)
+			}
+		}
+		if end-start >= maxXnameRangeSize-len(numbers) {
+			return nil, fmt.Errorf("xname range %q is too large", pattern)
+		}
+		for i := start; i <= end; i++ {
+			numbers = append(numbers, i)
+		}
+	}
+
+	var xnames []string
+	for _, n := range numbers {
+		expanded, err := ExpandXnameRange(prefix + strconv.Itoa(n) + suffix)
+		if err != nil {
+			return nil, err
+		}
+		xnames = append(xnames, expanded...)
+		if len(xnames) > maxXnameRangeSize {
+			return nil, fmt.Errorf("xname range %q is too large", pattern)
+		}
+	}
+	return xnames, nil
+}
+
+// xnameTypes maps the xname format of each component type to its type.
+var xnameTypes = []struct {
+	regex *regexp.Regexp
+	typ   ComponentType
+}{
+	{regexp.MustCompile(`^d\d+$`), TypeCDU},
+	{regexp.MustCompile(`^d\d+w\d+$`), TypeCDUMgmtSwitch},
+	{regexp.MustCompile(`^x\d+$`), TypeCabinet},
+	{regexp.MustCompile(`^x\d+d\d+$`), TypeCabinetCDU},
+	{regexp.MustCompile(`^x\d+m\d+$`), TypeCabinetPDUController},
+	{regexp.MustCompile(`^x\d+m\d+p\d+$`), TypeCabinetPDU},
+	{regexp.MustCompile(`^x\d+m\d+p\d+j\d+$`), TypeCabinetPDUOutlet},
+	{regexp.MustCompile(`^x\d+m\d+p\d+v\d+$`), TypeCabinetPDUPowerConnector},
+	{regexp.MustCompile(`^x\d+e\d+$`), TypeCEC},
+	{regexp.MustCompile(`^x\d+c\d+$`), TypeChassis},
+	{regexp.MustCompile(`^x\d+c\d+b\d+$`), TypeChassisBMC},
+	{regexp.MustCompile(`^x\d+c\d+t\d+$`), TypeCMMRectifier},
+	{regexp.MustCompile(`^x\d+c\d+f\d+$`), TypeCMMFpga},
+	{regexp.MustCompile(`^x\d+c\d+w\d+$`), TypeMgmtSwitch},
+	{regexp.MustCompile(`^x\d+c\d+h\d+$`), TypeMgmtHLSwitch},
+	{regexp.MustCompile(`^x\d+c\d+s\d+$`), TypeComputeModule},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+$`), TypeNodeBMC},
+	{regexp.MustCompile(`^x\d+c\d+s\d+e\d+$`), TypeNodeEnclosure},
+	{regexp.MustCompile(`^x\d+c\d+s\d+e\d+t\d+$`), TypeNodeEnclosurePowerSupply},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+f\d+$`), TypeNodeFpga},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+$`), TypeNode},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+p\d+$`), TypeProcessor},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+d\d+$`), TypeMemory},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+i\d+$`), TypeNodeNIC},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+a\d+$`), TypeNodeAccel},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+r\d+$`), TypeNodeAccelRiser},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+g\d+$`), TypeStorageGroup},
+	{regexp.MustCompile(`^x\d+c\d+s\d+b\d+n\d+g\d+k\d+$`), TypeDrive},
+	{regexp.MustCompile(`^x\d+c\d+r\d+$`), TypeRouterModule},
+	{regexp.MustCompile(`^x\d+c\d+r\d+b\d+$`), TypeRouterBMC},
+	{regexp.MustCompile(`^x\d+c\d+r\d+e\d+$`), TypeHSNBoard},
+	{regexp.MustCompile(`^x\d+c\d+r\d+f\d+$`), TypeRouterFpga},
+	{regexp.MustCompile(`^x\d+c\d+r\d+a\d+$`), TypeHSNAsic},
+	{regexp.MustCompile(`^x\d+c\d+r\d+a\d+l\d+$`), TypeHSNLink},
+	{regexp.MustCompile(`^x\d+c\d+r\d+j\d+$`), TypeHSNConnector},
+}
+
+// XnameType returns the component type implied by the format of an xname, or
+// TypeINVALID if the format is not recognized.
+func XnameType(xname string) ComponentType {
+	for _, t := range xnameTypes {
+		if t.regex.MatchString(xname) {
+			return t.typ
+		}
+	}
+	return TypeINVALID
+}
+
+// XnameLess orders xnames by location, comparing the numbers in each segment
+// numerically so that x1000c2 sorts before x1000c10.
+func XnameLess(a, b string) bool {
+	as, bs := XnameSegments(a), XnameSegments(b)
+	if as == nil || bs == nil {
+		return a < b
+	}
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		ap, an := splitXnameSegment(as[i])
+		bp, bn := splitXnameSegment(bs[i])
+		if ap != bp {
+			return ap < bp
+		}
+		return an < bn
+	}
+	return len(as) < len(bs)
+}
+
+func splitXnameSegment(segment string) (string, int) {
+	i := strings.IndexAny(segment, "0123456789")
+	n, _ := strconv.Atoi(segment[i:])
+	return segment[:i], n
+}
@@ -0,0 +1,38 @@
+package csm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpandXnameRange(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+		ok      bool
+	}{
+		{"x1000c0s0b0n0", []string{"x1000c0s0b0n0"}, true},
+		{"x1000c0s[0-1,7]b0n0", []string{"x1000c0s0b0n0", "x1000c0s1b0n0", "x1000c0s7b0n0"}, true},
+		{"x1000c[0-1]s0b0n[0-1]", []string{"x1000c0s0b0n0", "x1000c0s0b0n1", "x1000c1s0b0n0", "x1000c1s0b0n1"}, true},
+		{"x1000c0s[1-0]b0n0", nil, false},
+		{"x1000c0s[+1]b0n0", nil, false},
+		{"x1000c0s[0-1b0n0", nil, false},
+		{"x1000c0s[0-65535]", nil, true},
+		{"x[0-65536]", nil, false},
+		{"x[0-9223372036854775807]", nil, false},
+		{"x[9223372036854775807]", nil, false},
+		{"x[0-30000,0-40000]", nil, false},
+		{"x[0-999]c[0-999]", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := ExpandXnameRange(tt.pattern)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ExpandXnameRange() error = %v, want ok %v", err, tt.ok)
+			}
+			if tt.want != nil && !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandXnameRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,5 +4,5 @@ package schemas
 type Envelope struct {
 	SchemaID string      `json:"schema_id"`
 	Version  string      `json:"version"`
-	Payload  interface{} `json:"payload"`
+	Payload  interface{} `json:"payload,omitempty"`
 }
@@ -1,15 +1,42 @@
 package schemas
 
+import "net/netip"
+
+// Link states reported in EthernetInterface.LinkStatus.
+const (
+	LinkUp   = "LinkUp"
+	LinkDown = "LinkDown"
+	NoLink   = "NoLink"
+)
+
+type InterfaceAddress struct {
+	Address      IPAddress `json:"address"`                    // IPv4 or IPv6 address
+	PrefixLength int       `json:"prefix_length,omitempty"`    // Length of the network prefix, e.g. 24
+	Origin       string    `json:"origin,omitempty"`           // How the address was assigned, e.g. DHCP, Static or SLAAC
+	Gateway      IPAddress `json:"gateway,omitempty,omitzero"` // Gateway for the address
+}
+
+type VLAN struct {
+	ID      int            `json:"id"`                         // VLAN ID
+	Enabled Optional[bool] `json:"enabled,omitempty,omitzero"` // Whether the VLAN is enabled on the interface
+}
+
 type EthernetInterface struct {
-	URI         string `json:"uri,omitempty"`         // URI of the interface
-	MAC         string `json:"mac,omitempty"`         // MAC address of the interface
-	IP          string `json:"ip,omitempty"`          // IP address of the interface
-	Name        string `json:"name,omitempty"`        // Name of the interface
-	Description string `json:"description,omitempty"` // Description of the interface
-	Enabled     bool   `json:"enabled,omitempty"`     // Whether interface is enabled
+	URI         string             `json:"uri,omitempty"`              // URI of the interface
+	MAC         MACAddress         `json:"mac,omitempty,omitzero"`     // MAC address of the interface
+	IP          IPAddress          `json:"ip,omitempty,omitzero"`      // IP address of the interface
+	Name        string             `json:"name,omitempty"`             // Name of the interface
+	Description string             `json:"description,omitempty"`      // Description of the interface
+	Enabled     Optional[bool]     `json:"enabled,omitempty,omitzero"` // Whether interface is enabled, if known
+	LinkStatus  string             `json:"link_status,omitempty"`      // Link status, e.g. LinkUp, LinkDown or NoLink
+	SpeedMbps   int                `json:"speed_mbps,omitempty"`       // Link speed in Mbit/s
+	MTU         int                `json:"mtu,omitempty"`              // Maximum transmission unit in bytes
+	VLANs       []VLAN             `json:"vlans,omitempty"`            // VLANs configured on the interface
+	Addresses   []InterfaceAddress `json:"addresses,omitempty"`        // All IPv4 and IPv6 addresses of the interface
 }
 
 type NetworkAdapter struct {
+	ID           string `json:"id,omitempty"`           // ID of the adapter, referenced by NetworkInterface.AdapterID
 	URI          string `json:"uri,omitempty"`          // URI of the adapter
 	Manufacturer string `json:"manufacturer,omitempty"` // Manufacturer of the adapter
 	Name         string `json:"name,omitempty"`         // Name of the adapter
@@ -19,10 +46,70 @@ type NetworkAdapter struct {
 }
 
 type NetworkInterface struct {
-	URI         string         `json:"uri,omitempty"`         // URI of the interface
-	Name        string         `json:"name,omitempty"`        // Name of the interface
-	Description string         `json:"description,omitempty"` // Description of the interface
-	Adapter     NetworkAdapter `json:"adapter,omitempty"`     // Adapter of the interface
+	URI         string          `json:"uri,omitempty"`         // URI of the interface
+	Name        string          `json:"name,omitempty"`        // Name of the interface
+	Description string          `json:"description,omitempty"` // Description of the interface
+	AdapterID   string          `json:"adapter_id,omitempty"`  // ID of the adapter in NetworkAdapters (version 2)
+	Adapter     *NetworkAdapter `json:"adapter,omitempty"`     // Adapter of the interface (version 1).  Deprecated: use AdapterID
+}
+
+type Processor struct {
+	URI            string `json:"uri,omitempty"`             // URI of the processor
+	Socket         string `json:"socket,omitempty"`          // Socket the processor occupies
+	Architecture   string `json:"architecture,omitempty"`    // Architecture of the processor, e.g. x86
+	InstructionSet string `json:"instruction_set,omitempty"` // Instruction set of the processor, e.g. x86-64
+	Manufacturer   string `json:"manufacturer,omitempty"`    // Manufacturer of the processor
+	Model          string `json:"model,omitempty"`           // Model of the processor
+	Serial         string `json:"serial,omitempty"`          // Serial number of the processor
+	Firmware       string `json:"firmware,omitempty"`        // Firmware (microcode) version of the processor
+	TotalCores     int    `json:"total_cores,omitempty"`     // Number of cores
+	TotalThreads   int    `json:"total_threads,omitempty"`   // Number of hardware threads
+	MaxSpeedMHz    int    `json:"max_speed_mhz,omitempty"`   // Maximum clock speed in MHz
+}
+
+type MemoryModule struct {
+	URI          string       `json:"uri,omitempty"`          // URI of the module
+	Name         string       `json:"name,omitempty"`         // Name of the module, often its slot
+	DeviceType   string       `json:"device_type,omitempty"`  // Memory device type, e.g. DDR5
+	Manufacturer string       `json:"manufacturer,omitempty"` // Manufacturer of the module
+	PartNumber   string       `json:"part_number,omitempty"`  // Part number of the module
+	Serial       string       `json:"serial,omitempty"`       // Serial number of the module
+	Firmware     string       `json:"firmware,omitempty"`     // Firmware revision of the module
+	Capacity     ByteQuantity `json:"capacity,omitempty"`     // Capacity of the module
+	SpeedMHz     int          `json:"speed_mhz,omitempty"`    // Operating speed of the module in MHz
+}
+
+type Drive struct {
+	URI          string       `json:"uri,omitempty"`          // URI of the drive
+	Name         string       `json:"name,omitempty"`         // Name of the drive
+	MediaType    string       `json:"media_type,omitempty"`   // Media type, e.g. SSD or HDD
+	Protocol     string       `json:"protocol,omitempty"`     // Protocol of the drive, e.g. NVMe or SATA
+	Manufacturer string       `json:"manufacturer,omitempty"` // Manufacturer of the drive
+	Model        string       `json:"model,omitempty"`        // Model of the drive
+	Serial       string       `json:"serial,omitempty"`       // Serial number of the drive
+	Firmware     string       `json:"firmware,omitempty"`     // Firmware revision of the drive
+	Capacity     ByteQuantity `json:"capacity,omitempty"`     // Capacity of the drive
+}
+
+type Accelerator struct {
+	URI          string       `json:"uri,omitempty"`          // URI of the accelerator
+	Type         string       `json:"type,omitempty"`         // Type of accelerator, e.g. GPU or FPGA
+	Manufacturer string       `json:"manufacturer,omitempty"` // Manufacturer of the accelerator
+	Model        string       `json:"model,omitempty"`        // Model of the accelerator
+	Serial       string       `json:"serial,omitempty"`       // Serial number of the accelerator
+	Firmware     string       `json:"firmware,omitempty"`     // Firmware version of the accelerator
+	Memory       ByteQuantity `json:"memory,omitempty"`       // Memory on the accelerator
+}
+
+type PCIeDevice struct {
+	URI          string `json:"uri,omitempty"`          // URI of the device
+	Name         string `json:"name,omitempty"`         // Name of the device
+	DeviceType   string `json:"device_type,omitempty"`  // Device type, e.g. SingleFunction or MultiFunction
+	Manufacturer string `json:"manufacturer,omitempty"` // Manufacturer of the device
+	Model        string `json:"model,omitempty"`        // Model of the device
+	PartNumber   string `json:"part_number,omitempty"`  // Part number of the device
+	Serial       string `json:"serial,omitempty"`       // Serial number of the device
+	Firmware     string `json:"firmware,omitempty"`     // Firmware version of the device
 }
 
 type InventoryDetail struct {
@@ -36,15 +123,119 @@ type InventoryDetail struct {
 	BiosVersion          string              `json:"bios_version,omitempty"`         // Version of the BIOS
 	EthernetInterfaces   []EthernetInterface `json:"ethernet_interfaces,omitempty"`  // Ethernet interfaces of the Node
 	NetworkInterfaces    []NetworkInterface  `json:"network_interfaces,omitempty"`   // Network interfaces of the Node
+	NetworkAdapters      []NetworkAdapter    `json:"network_adapters,omitempty"`     // Network adapters of the Node
 	PowerState           string              `json:"power_state,omitempty"`          // Power state of the Node
 	ProcessorCount       int                 `json:"processor_count,omitempty"`      // Processors of the Node
 	ProcessorType        string              `json:"processor_type,omitempty"`       // Processor type of the Node
-	MemoryTotal          float32             `json:"memory_total,omitempty"`         // Total memory of the Node in Gigabytes
-	TrustedModules       []string            `json:"trusted_modules,omitempty"`      // Trusted modules of the Node
-	TrustedComponents    []string            `json:"trusted_components,omitempty"`   // Trusted components of the Chassis
-	Chassis_SKU          string              `json:"chassis_sku,omitempty"`          // SKU of the Chassis
-	Chassis_Serial       string              `json:"chassis_serial,omitempty"`       // Serial number of the Chassis
-	Chassis_AssetTag     string              `json:"chassis_asset_tag,omitempty"`    // Asset tag of the Chassis
-	Chassis_Manufacturer string              `json:"chassis_manufacturer,omitempty"` // Manufacturer of the Chassis
-	Chassis_Model        string              `json:"chassis_model,omitempty"`        // Model of the Chassis
+	Memory               ByteQuantity        `json:"memory,omitempty"`               // Total memory of the Node (version 2)
+	MemoryTotal          float32             `json:"memory_total,omitempty"`         // Total memory of the Node in GiB (version 1).  Deprecated: use Memory
+	Processors           []Processor         `json:"processors,omitempty"`           // CPUs of the Node
+	MemoryModules        []MemoryModule      `json:"memory_modules,omitempty"`       // Memory DIMMs of the Node
+	Drives               []Drive             `json:"drives,omitempty"`               // Drives of the Node
+	Accelerators         []Accelerator       `json:"accelerators,omitempty"`         // GPUs and other accelerators of the Node
+	PCIeDevices          []PCIeDevice        `json:"pcie_devices,omitempty"`         // PCIe devices of the Node
+	TrustedModules       []TrustedModule     `json:"trusted_modules,omitempty"`      // Trusted modules (TPMs) of the Node
+	TrustedComponents    []TrustedComponent  `json:"trusted_components,omitempty"`   // Trusted components of the Chassis
+	Chassis              *ChassisDetail      `json:"chassis,omitempty"`              // Chassis of the Node (version 2)
+	Chassis_SKU          string              `json:"chassis_sku,omitempty"`          // SKU of the Chassis (version 1).  Deprecated: use Chassis.SKU
+	Chassis_Serial       string              `json:"chassis_serial,omitempty"`       // Serial number of the Chassis (version 1).  Deprecated: use Chassis.Serial
+	Chassis_AssetTag     string              `json:"chassis_asset_tag,omitempty"`    // Asset tag of the Chassis (version 1).  Deprecated: use Chassis.AssetTag
+	Chassis_Manufacturer string              `json:"chassis_manufacturer,omitempty"` // Manufacturer of the Chassis (version 1).  Deprecated: use Chassis.Manufacturer
+	Chassis_Model        string              `json:"chassis_model,omitempty"`        // Model of the Chassis (version 1).  Deprecated: use Chassis.Model
+}
+
+// TotalMemory returns the combined capacity of the memory modules.
+func (d InventoryDetail) TotalMemory() ByteQuantity {
+	var total ByteQuantity
+	for _, m := range d.MemoryModules {
+		total += m.Capacity
+	}
+	return total
+}
+
+// TotalDriveCapacity returns the combined capacity of the drives.
+func (d InventoryDetail) TotalDriveCapacity() ByteQuantity {
+	var total ByteQuantity
+	for _, drive := range d.Drives {
+		total += drive.Capacity
+	}
+	return total
+}
+
+// Summarize fills ProcessorCount, ProcessorType and Memory from Processors
+// and MemoryModules, so that consumers that only read the summary fields see
+// the same totals.  Summary fields that are already set are kept.
+func (d *InventoryDetail) Summarize() {
+	if d.ProcessorCount == 0 {
+		d.ProcessorCount = len(d.Processors)
+	}
+	if d.ProcessorType == "" && len(d.Processors) > 0 {
+		d.ProcessorType = d.Processors[0].Model
+	}
+	if d.Memory == 0 && d.MemoryTotal == 0 {
+		d.Memory = d.TotalMemory()
+	}
+}
+
+// IsUp reports whether the interface is not known to be disabled and its link
+// is not known to be down.
+func (e EthernetInterface) IsUp() bool {
+	return e.Enabled.OrElse(true) && (e.LinkStatus == "" || e.LinkStatus == LinkUp)
+}
+
+// AllAddresses returns IP followed by any other Addresses.
+func (e EthernetInterface) AllAddresses() []IPAddress {
+	var all []IPAddress
+	if e.IP.IsValid() {
+		all = append(all, e.IP)
+	}
+	for _, a := range e.Addresses {
+		if a.Address.IsValid() && a.Address != e.IP {
+			all = append(all, a.Address)
+		}
+	}
+	return all
+}
+
+// Adapter returns the network adapter with the given ID.
+func (d InventoryDetail) Adapter(id string) (NetworkAdapter, bool) {
+	for _, a := range d.NetworkAdapters {
+		if id != "" && a.ID == id {
+			return a, true
+		}
+	}
+	return NetworkAdapter{}, false
+}
+
+// InterfaceByMAC returns the Ethernet interface with the given MAC address.
+func (d InventoryDetail) InterfaceByMAC(mac MACAddress) (EthernetInterface, bool) {
+	for _, e := range d.EthernetInterfaces {
+		if mac.IsValid() && e.MAC == mac {
+			return e, true
+		}
+	}
+	return EthernetInterface{}, false
+}
+
+// ManagementInterface returns the node's interface on the management network:
+// the first interface that is up and has an address in one of networks.  With
+// no networks, the first interface that is up and has an IPv4 address is used.
+func (d InventoryDetail) ManagementInterface(networks ...netip.Prefix) (EthernetInterface, bool) {
+	for _, e := range d.EthernetInterfaces {
+		if !e.IsUp() {
+			continue
+		}
+		for _, ip := range e.AllAddresses() {
+			addr := ip.Addr()
+			if len(networks) == 0 && addr.Is4() {
+				return e, true
+			}
+			for _, network := range networks {
+				if network.Contains(addr) {
+					return e, true
+				}
+			}
+		}
+	}
+	return EthernetInterface{}, false
 }
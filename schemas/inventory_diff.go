@@ -0,0 +1,331 @@
+package schemas
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ChangeKind says whether something was added, removed or modified.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "Added"
+	ChangeRemoved  ChangeKind = "Removed"
+	ChangeModified ChangeKind = "Modified"
+)
+
+// ChangeCategory classifies a change for operators.
+type ChangeCategory string
+
+const (
+	CategoryFRUReplacement ChangeCategory = "FRUReplacement" // A serial number changed, so the part was swapped
+	CategoryFirmwareUpdate ChangeCategory = "FirmwareUpdate" // A firmware or BIOS version changed
+	CategoryHardware       ChangeCategory = "Hardware"       // A part was added or removed
+	CategoryOther          ChangeCategory = "Other"          // Any other attribute changed
+)
+
+// InventoryChange is a single difference between two snapshots.  Path locates
+// the change using JSON field names, with list elements identified by their
+// key, e.g. ethernet_interfaces[ae:12:e2:ff:89:9d].ip.  For added and removed
+// list elements, New or Old holds the element's key.
+type InventoryChange struct {
+	Path     string         `json:"path"`
+	Kind     ChangeKind     `json:"kind" jsonschema:"enum=Added,enum=Removed,enum=Modified"`
+	Category ChangeCategory `json:"category" jsonschema:"enum=FRUReplacement,enum=FirmwareUpdate,enum=Hardware,enum=Other"`
+	Old      string         `json:"old,omitempty"`
+	New      string         `json:"new,omitempty"`
+}
+
+// InventoryDiff is the change report produced by DiffInventory.
+type InventoryDiff struct {
+	UUID    string            `json:"uuid,omitempty"` // UUID of the Node
+	Changes []InventoryChange `json:"changes"`
+}
+
+// Empty reports whether the snapshots were the same.
+func (d InventoryDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Category returns the changes in category c.
+func (d InventoryDiff) Category(c ChangeCategory) []InventoryChange {
+	var changes []InventoryChange
+	for _, change := range d.Changes {
+		if change.Category == c {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// DiffInventory compares two snapshots of the same node.  Both are converted
+// to the version 2 shape first.  List elements are matched by identity rather
+// than position: EthernetInterfaces by MAC then URI, NetworkInterfaces by URI
+// then the serial number of their adapter, NetworkAdapters by ID, URI then
+// serial, Processors by socket, MemoryModules by slot name, and other parts by
+// URI then serial.  Elements with none of these, including TrustedModules,
+// which have no identity of their own, are matched by position among
+// themselves.  Changes are reported in path order.
+func DiffInventory(old, new InventoryDetail) InventoryDiff {
+	diff := InventoryDiff{UUID: new.UUID}
+	if diff.UUID == "" {
+		diff.UUID = old.UUID
+	}
+	d := differ{old: old.ToV2(), new: new.ToV2(), changes: []InventoryChange{}}
+	d.value("", reflect.ValueOf(d.old), reflect.ValueOf(d.new))
+	diff.Changes = d.changes
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Path < diff.Changes[j].Path
+	})
+	return diff
+}
+
+// keyed is implemented by list elements that can be matched by identity.
+// Keys are tried in order; an empty key is skipped.  d is the snapshot the
+// element belongs to, for keys that refer to other parts of it.
+type keyed interface {
+	diffKeys(d InventoryDetail) []string
+}
+
+func (e EthernetInterface) diffKeys(InventoryDetail) []string { return []string{e.MAC.String(), e.URI} }
+func (a NetworkAdapter) diffKeys(InventoryDetail) []string    { return []string{a.ID, a.URI, a.Serial} }
+func (p Processor) diffKeys(InventoryDetail) []string         { return []string{p.Socket, p.URI, p.Serial} }
+func (m MemoryModule) diffKeys(InventoryDetail) []string      { return []string{m.Name, m.URI, m.Serial} }
+func (d Drive) diffKeys(InventoryDetail) []string             { return []string{d.URI, d.Serial} }
+func (a Accelerator) diffKeys(InventoryDetail) []string       { return []string{a.URI, a.Serial} }
+func (p PCIeDevice) diffKeys(InventoryDetail) []string        { return []string{p.URI, p.Serial} }
+func (c TrustedComponent) diffKeys(InventoryDetail) []string {
+	return []string{c.URI, c.UUID, c.Serial}
+}
+
+// AdapterID is local to a snapshot, and ToV2 generates it for version 1
+// input, so interfaces are matched by their adapter's serial number instead.
+func (n NetworkInterface) diffKeys(d InventoryDetail) []string {
+	a, _ := d.Adapter(n.AdapterID)
+	return []string{n.URI, a.Serial}
+}
+
+// differ accumulates the changes between two version 2 snapshots.
+type differ struct {
+	old, new InventoryDetail
+	changes  []InventoryChange
+}
+
+var (
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	keyedType    = reflect.TypeOf((*keyed)(nil)).Elem()
+)
+
+func (d *differ) value(path string, o, n reflect.Value) {
+	switch {
+	case o.Type().Implements(stringerType) || o.Kind() != reflect.Struct && o.Kind() != reflect.Slice && o.Kind() != reflect.Pointer:
+		if !reflect.DeepEqual(o.Interface(), n.Interface()) {
+			d.changes = append(d.changes, InventoryChange{
+				Path:     path,
+				Kind:     ChangeModified,
+				Category: categorize(path),
+				Old:      format(o),
+				New:      format(n),
+			})
+		}
+	case o.Kind() == reflect.Pointer:
+		switch {
+		case o.IsNil() && n.IsNil():
+		case o.IsNil():
+			d.changes = append(d.changes, InventoryChange{Path: path, Kind: ChangeAdded, Category: CategoryHardware})
+		case n.IsNil():
+			d.changes = append(d.changes, InventoryChange{Path: path, Kind: ChangeRemoved, Category: CategoryHardware})
+		default:
+			d.value(path, o.Elem(), n.Elem())
+		}
+	case o.Kind() == reflect.Struct:
+		t := o.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := jsonName(f)
+			if name == "" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			d.value(name, o.Field(i), n.Field(i))
+		}
+	case o.Kind() == reflect.Slice:
+		d.slice(path, o, n)
+	}
+}
+
+func (d *differ) slice(path string, o, n reflect.Value) {
+	elem := o.Type().Elem()
+	if elem.Kind() != reflect.Struct || elem.Implements(stringerType) {
+		d.set(path, o, n)
+		return
+	}
+
+	// Match elements by each key in turn, then by position for any that
+	// have no keys at all.
+	oldKeys, newKeys := keys(o, d.old), keys(n, d.new)
+	matchedOld := make([]int, o.Len())
+	matchedNew := make([]bool, n.Len())
+	for i := range matchedOld {
+		matchedOld[i] = -1
+	}
+	passes := 0
+	for _, k := range append(oldKeys, newKeys...) {
+		passes = max(passes, len(k))
+	}
+	for pass := 0; pass < passes; pass++ {
+		for i := range matchedOld {
+			if matchedOld[i] >= 0 || pass >= len(oldKeys[i]) || oldKeys[i][pass] == "" {
+				continue
+			}
+			for j := range matchedNew {
+				if !matchedNew[j] && pass < len(newKeys[j]) && newKeys[j][pass] == oldKeys[i][pass] {
+					matchedOld[i], matchedNew[j] = j, true
+					break
+				}
+			}
+		}
+	}
+	var keylessOld, keylessNew []int
+	for i, j := range matchedOld {
+		if j < 0 && keyless(oldKeys[i]) {
+			keylessOld = append(keylessOld, i)
+		}
+	}
+	for j, matched := range matchedNew {
+		if !matched && keyless(newKeys[j]) {
+			keylessNew = append(keylessNew, j)
+		}
+	}
+	for k := 0; k < len(keylessOld) && k < len(keylessNew); k++ {
+		matchedOld[keylessOld[k]], matchedNew[keylessNew[k]] = keylessNew[k], true
+	}
+
+	for i, j := range matchedOld {
+		if j < 0 {
+			d.changes = append(d.changes, InventoryChange{
+				Path:     elementPath(path, oldKeys[i], i),
+				Kind:     ChangeRemoved,
+				Category: CategoryHardware,
+				Old:      elementKey(oldKeys[i], i),
+			})
+			continue
+		}
+		d.value(elementPath(path, newKeys[j], j), o.Index(i), n.Index(j))
+	}
+	for j, matched := range matchedNew {
+		if !matched {
+			d.changes = append(d.changes, InventoryChange{
+				Path:     elementPath(path, newKeys[j], j),
+				Kind:     ChangeAdded,
+				Category: CategoryHardware,
+				New:      elementKey(newKeys[j], j),
+			})
+		}
+	}
+}
+
+// diffSet compares lists of scalar values without regard to order.
+func (d *differ) set(path string, o, n reflect.Value) {
+	count := map[string]int{}
+	for i := 0; i < o.Len(); i++ {
+		count[format(o.Index(i))]--
+	}
+	for i := 0; i < n.Len(); i++ {
+		count[format(n.Index(i))]++
+	}
+	values := make([]string, 0, len(count))
+	for v := range count {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		for ; count[v] < 0; count[v]++ {
+			d.changes = append(d.changes, InventoryChange{Path: path, Kind: ChangeRemoved, Category: CategoryHardware, Old: v})
+		}
+		for ; count[v] > 0; count[v]-- {
+			d.changes = append(d.changes, InventoryChange{Path: path, Kind: ChangeAdded, Category: CategoryHardware, New: v})
+		}
+	}
+}
+
+func keys(list reflect.Value, d InventoryDetail) [][]string {
+	k := make([][]string, list.Len())
+	for i := range k {
+		if e, ok := list.Index(i).Interface().(keyed); ok {
+			k[i] = e.diffKeys(d)
+		}
+	}
+	return k
+}
+
+// keyless reports whether an element has no non-empty key.
+func keyless(keys []string) bool {
+	for _, k := range keys {
+		if k != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// elementKey is the first non-empty key of an element, or its index.
+func elementKey(keys []string, index int) string {
+	for _, k := range keys {
+		if k != "" {
+			return k
+		}
+	}
+	return fmt.Sprint(index)
+}
+
+func elementPath(path string, keys []string, index int) string {
+	return fmt.Sprintf("%s[%s]", path, elementKey(keys, index))
+}
+
+var elementKeyRegex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// FieldPath returns an InventoryChange, Provenance or MergeConflict path
+// without its list element keys, e.g. ethernet_interfaces[ae:12:e2:ff:89:9d].ip
+// becomes ethernet_interfaces.ip, the form MergePolicy.Fields uses.
+func FieldPath(path string) string {
+	return elementKeyRegex.ReplaceAllString(path, "")
+}
+
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+func format(v reflect.Value) string {
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// categorize classifies a modified field by its name.
+func categorize(path string) ChangeCategory {
+	field := path[strings.LastIndex(path, ".")+1:]
+	switch field {
+	case "serial", "uuid", "ek_certificate":
+		return CategoryFRUReplacement
+	case "firmware", "bios_version":
+		return CategoryFirmwareUpdate
+	}
+	return CategoryOther
+}
@@ -0,0 +1,138 @@
+package schemas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffInventoryIdentical(t *testing.T) {
+	d := InventoryDetail{
+		UUID:               "4c4c4544-0042-3610-8052-b3c04f333333",
+		Processors:         []Processor{{Socket: "CPU 1"}, {Socket: "CPU 2"}},
+		EthernetInterfaces: []EthernetInterface{{MAC: MustParseMACAddress("b0:7b:25:c8:2a:10")}, {MAC: MustParseMACAddress("b0:7b:25:c8:2a:11")}},
+	}
+	reordered := d
+	reordered.Processors = []Processor{{Socket: "CPU 2"}, {Socket: "CPU 1"}}
+	reordered.EthernetInterfaces = []EthernetInterface{{MAC: MustParseMACAddress("B0-7B-25-C8-2A-11")}, {MAC: MustParseMACAddress("b0:7b:25:c8:2a:10")}}
+
+	diff := DiffInventory(d, reordered)
+	if !diff.Empty() || diff.Changes == nil {
+		t.Errorf("DiffInventory() of reordered lists = %+v, want no changes", diff.Changes)
+	}
+	if diff.UUID != d.UUID {
+		t.Errorf("UUID = %q", diff.UUID)
+	}
+}
+
+func TestDiffInventoryCategories(t *testing.T) {
+	old := InventoryDetail{
+		BiosVersion: "1.10.2",
+		PowerState:  "On",
+		Processors:  []Processor{{Socket: "CPU 1", Serial: "P1"}},
+		Drives:      []Drive{{URI: "/Drives/0", Serial: "D0"}},
+	}
+	new := InventoryDetail{
+		BiosVersion: "1.11.0",
+		PowerState:  "Off",
+		Processors:  []Processor{{Socket: "CPU 1", Serial: "P2"}},
+		Drives:      []Drive{{URI: "/Drives/1", Serial: "D1"}},
+	}
+	want := []InventoryChange{
+		{Path: "bios_version", Kind: ChangeModified, Category: CategoryFirmwareUpdate, Old: "1.10.2", New: "1.11.0"},
+		{Path: "drives[/Drives/0]", Kind: ChangeRemoved, Category: CategoryHardware, Old: "/Drives/0"},
+		{Path: "drives[/Drives/1]", Kind: ChangeAdded, Category: CategoryHardware, New: "/Drives/1"},
+		{Path: "power_state", Kind: ChangeModified, Category: CategoryOther, Old: "On", New: "Off"},
+		{Path: "processors[CPU 1].serial", Kind: ChangeModified, Category: CategoryFRUReplacement, Old: "P1", New: "P2"},
+	}
+	diff := DiffInventory(old, new)
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("DiffInventory() = %+v, want %+v", diff.Changes, want)
+	}
+	if got := diff.Category(CategoryHardware); len(got) != 2 {
+		t.Errorf("Category(Hardware) = %+v", got)
+	}
+}
+
+func TestDiffInventoryMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new InventoryDetail
+		want     []InventoryChange
+	}{
+		{
+			name: "MAC before URI",
+			old:  InventoryDetail{EthernetInterfaces: []EthernetInterface{{MAC: MustParseMACAddress("b0:7b:25:c8:2a:10"), URI: "/EthernetInterfaces/1"}}},
+			new:  InventoryDetail{EthernetInterfaces: []EthernetInterface{{MAC: MustParseMACAddress("b0:7b:25:c8:2a:10"), URI: "/EthernetInterfaces/NIC.1"}}},
+			want: []InventoryChange{{Path: "ethernet_interfaces[b0:7b:25:c8:2a:10].uri", Kind: ChangeModified, Category: CategoryOther, Old: "/EthernetInterfaces/1", New: "/EthernetInterfaces/NIC.1"}},
+		},
+		{
+			name: "URI when the MAC is missing",
+			old:  InventoryDetail{EthernetInterfaces: []EthernetInterface{{URI: "/EthernetInterfaces/1"}}},
+			new:  InventoryDetail{EthernetInterfaces: []EthernetInterface{{MAC: MustParseMACAddress("b0:7b:25:c8:2a:10"), URI: "/EthernetInterfaces/1"}}},
+			want: []InventoryChange{{Path: "ethernet_interfaces[b0:7b:25:c8:2a:10].mac", Kind: ChangeModified, Category: CategoryOther, New: "b0:7b:25:c8:2a:10"}},
+		},
+		{
+			name: "keyless elements by position",
+			old:  InventoryDetail{Drives: []Drive{{Name: "SSD", Capacity: 480 * GB}, {URI: "/Drives/1"}}},
+			new:  InventoryDetail{Drives: []Drive{{URI: "/Drives/1"}, {Name: "SSD", Capacity: 960 * GB}}},
+			want: []InventoryChange{{Path: "drives[1].capacity", Kind: ChangeModified, Category: CategoryOther, Old: "480GB", New: "960GB"}},
+		},
+		{
+			name: "trusted modules by position",
+			old:  InventoryDetail{TrustedModules: []TrustedModule{{InterfaceType: "TPM1_2", EKCertificate: "old"}}},
+			new:  InventoryDetail{TrustedModules: []TrustedModule{{InterfaceType: "TPM2_0", EKCertificate: "new"}}},
+			want: []InventoryChange{
+				{Path: "trusted_modules[0].ek_certificate", Kind: ChangeModified, Category: CategoryFRUReplacement, Old: "old", New: "new"},
+				{Path: "trusted_modules[0].interface_type", Kind: ChangeModified, Category: CategoryOther, Old: "TPM1_2", New: "TPM2_0"},
+			},
+		},
+		{
+			name: "network interfaces by adapter serial",
+			old: InventoryDetail{
+				NetworkInterfaces: []NetworkInterface{{Name: "NIC 1", Adapter: &NetworkAdapter{Serial: "MT1234"}}},
+			},
+			new: InventoryDetail{
+				NetworkAdapters:   []NetworkAdapter{{ID: "NIC.Slot.1", Serial: "MT1234"}},
+				NetworkInterfaces: []NetworkInterface{{Name: "NIC 1", AdapterID: "NIC.Slot.1"}},
+			},
+			want: []InventoryChange{
+				{Path: "network_adapters[NIC.Slot.1].id", Kind: ChangeModified, Category: CategoryOther, Old: "adapter0", New: "NIC.Slot.1"},
+				{Path: "network_interfaces[MT1234].adapter_id", Kind: ChangeModified, Category: CategoryOther, Old: "adapter0", New: "NIC.Slot.1"},
+			},
+		},
+		{
+			name: "scalar lists as sets",
+			old:  InventoryDetail{TrustedModules: []TrustedModule{{PCRBanks: []string{"SHA1", "SHA256"}}}},
+			new:  InventoryDetail{TrustedModules: []TrustedModule{{PCRBanks: []string{"SHA384", "SHA256"}}}},
+			want: []InventoryChange{
+				{Path: "trusted_modules[0].pcr_banks", Kind: ChangeRemoved, Category: CategoryHardware, Old: "SHA1"},
+				{Path: "trusted_modules[0].pcr_banks", Kind: ChangeAdded, Category: CategoryHardware, New: "SHA384"},
+			},
+		},
+		{
+			name: "chassis added",
+			old:  InventoryDetail{},
+			new:  InventoryDetail{Chassis: &ChassisDetail{Serial: "CN123"}},
+			want: []InventoryChange{{Path: "chassis", Kind: ChangeAdded, Category: CategoryHardware}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffInventory(tt.old, tt.new).Changes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffInventory() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"bios_version": "bios_version",
+		"ethernet_interfaces[ae:12:e2:ff:89:9d].ip":                "ethernet_interfaces.ip",
+		"ethernet_interfaces[ae:12:e2:ff:89:9d].vlans[10].enabled": "ethernet_interfaces.vlans.enabled",
+	} {
+		if got := FieldPath(path); got != want {
+			t.Errorf("FieldPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package schemas
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InventorySource is one report of a node's inventory, e.g. from Redfish, an
+// in-band agent or a CSV import.
+type InventorySource struct {
+	Name   string          `json:"name"` // Name of the source, matched against MergePolicy priorities
+	Time   time.Time       `json:"time"` // When the source collected the inventory
+	Detail InventoryDetail `json:"detail"`
+}
+
+// MergePolicy ranks sources, highest priority first.  Fields overrides
+// Priority for particular fields, keyed by JSON path, e.g. bios_version,
+// chassis.serial or ethernet_interfaces.ip; an entry for chassis applies to
+// every field below it.
+// Sources not listed rank below those that are, and between sources of equal
+// rank the most recent wins.
+type MergePolicy struct {
+	Priority []string            `json:"priority"`
+	Fields   map[string][]string `json:"fields,omitempty"`
+}
+
+// Provenance records which source a merged field came from.
+type Provenance struct {
+	Source string    `json:"source"`
+	Time   time.Time `json:"time"`
+}
+
+// ConflictValue is the value one source reported for a conflicting field.
+type ConflictValue struct {
+	Source string `json:"source"`
+	Value  string `json:"value"`
+}
+
+// MergeConflict is a field that sources disagreed on.
+type MergeConflict struct {
+	Field  string          `json:"field"`
+	Chosen string          `json:"chosen"` // Source whose value was used
+	Values []ConflictValue `json:"values"` // Every non-empty value, in priority order
+}
+
+// MergedInventory is the result of MergeInventory.  Provenance and Conflicts
+// use the same paths as InventoryChange, e.g. bios_version or
+// ethernet_interfaces[ae:12:e2:ff:89:9d].ip.
+type MergedInventory struct {
+	Detail     InventoryDetail       `json:"detail"`
+	Provenance map[string]Provenance `json:"provenance"`
+	Conflicts  []MergeConflict       `json:"conflicts,omitempty"`
+}
+
+// MergeInventory combines reports of the same node.  Every field is taken
+// from the highest priority source that reports it.  A field is reported when
+// it would be marshaled: Optional fields when set, even to false or 0, and
+// other fields when not empty, so a plain 0 cannot override another source.
+//
+// Lists whose elements all have an identity, as used by DiffInventory, are
+// merged element by element: the result holds every element any source
+// reported, in the order of the highest priority source, and each field of
+// an element is merged on its own.  MergePolicy.Fields applies to element
+// fields by their path without the key, e.g. ethernet_interfaces.ip.  Other
+// lists, and lists where an element has no identity or shares it with
+// another, are taken whole from one source.
+//
+// Details are converted to the version 2 shape first, and sources that report
+// different UUIDs are rejected.
+func MergeInventory(policy MergePolicy, sources ...InventorySource) (MergedInventory, error) {
+	merged := MergedInventory{Provenance: map[string]Provenance{}}
+	uuid := ""
+	for _, s := range sources {
+		if s.Detail.UUID == "" {
+			continue
+		}
+		if uuid != "" && !strings.EqualFold(uuid, s.Detail.UUID) {
+			return merged, fmt.Errorf("source %s reports UUID %s, expected %s", s.Name, s.Detail.UUID, uuid)
+		}
+		uuid = s.Detail.UUID
+	}
+
+	m := merger{policy: policy, sources: make([]InventorySource, len(sources)), result: &merged}
+	values := make([]sourceValue, len(sources))
+	for i, s := range sources {
+		s.Detail = s.Detail.ToV2()
+		m.sources[i] = s
+		values[i] = sourceValue{source: i, value: reflect.ValueOf(s.Detail)}
+	}
+	m.merge(reflect.ValueOf(&merged.Detail).Elem(), "", true, values)
+	return merged, nil
+}
+
+type sourceValue struct {
+	source int
+	value  reflect.Value
+}
+
+// merger merges the values that sources report at each path into result.
+type merger struct {
+	policy  MergePolicy
+	sources []InventorySource // With their details in the version 2 shape
+	result  *MergedInventory
+}
+
+// merge stores the merge of values, one per source reporting the enclosing
+// value, in dst.  omitEmpty says whether the field's tag omits it when empty.
+func (m *merger) merge(dst reflect.Value, path string, omitEmpty bool, values []sourceValue) {
+	t := dst.Type()
+	switch {
+	case t.Implements(stringerType):
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		var elems []sourceValue
+		for _, sv := range values {
+			if !sv.value.IsNil() {
+				elems = append(elems, sourceValue{source: sv.source, value: sv.value.Elem()})
+			}
+		}
+		if len(elems) == 0 {
+			return
+		}
+		v := reflect.New(t.Elem())
+		m.merge(v.Elem(), path, omitEmpty, elems)
+		if !v.Elem().IsZero() {
+			dst.Set(v)
+		}
+		return
+	case t.Kind() == reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := jsonName(f)
+			if name == "" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			fields := make([]sourceValue, len(values))
+			for j, sv := range values {
+				fields[j] = sourceValue{source: sv.source, value: sv.value.Field(i)}
+			}
+			m.merge(dst.Field(i), name, omits(f), fields)
+		}
+		return
+	case t.Kind() == reflect.Slice && t.Elem().Implements(keyedType):
+		if m.mergeList(dst, path, values) {
+			return
+		}
+	}
+	m.leaf(dst, path, omitEmpty, values)
+}
+
+// mergeList merges a list element by element.  Like DiffInventory, it
+// matches elements by each key in turn, so an element one source identifies
+// only by URI still matches one another source identifies by MAC and URI.
+// It reports false, merging nothing, if some element has no key or matches
+// the same element as another element of its source.
+func (m *merger) mergeList(dst reflect.Value, path string, values []sourceValue) bool {
+	type element struct {
+		keys   []string // The first non-empty value reported for each key
+		values []sourceValue
+	}
+	var elements []*element
+	for _, sv := range m.ranked(path, values) {
+		for i := 0; i < sv.value.Len(); i++ {
+			v := sv.value.Index(i)
+			keys := v.Interface().(keyed).diffKeys(m.sources[sv.source].Detail)
+			if keyless(keys) {
+				return false
+			}
+			var match *element
+			for pass := 0; pass < len(keys) && match == nil; pass++ {
+				if keys[pass] == "" {
+					continue
+				}
+				for _, e := range elements {
+					if pass < len(e.keys) && e.keys[pass] == keys[pass] {
+						match = e
+						break
+					}
+				}
+			}
+			if match == nil {
+				match = &element{}
+				elements = append(elements, match)
+			} else if match.values[len(match.values)-1].source == sv.source {
+				return false
+			}
+			for len(match.keys) < len(keys) {
+				match.keys = append(match.keys, "")
+			}
+			for k, key := range keys {
+				if match.keys[k] == "" {
+					match.keys[k] = key
+				}
+			}
+			match.values = append(match.values, sourceValue{source: sv.source, value: v})
+		}
+	}
+	if len(elements) == 0 {
+		return true
+	}
+
+	list := reflect.MakeSlice(dst.Type(), len(elements), len(elements))
+	for i, e := range elements {
+		m.merge(list.Index(i), elementPath(path, e.keys, i), true, e.values)
+	}
+	dst.Set(list)
+	return true
+}
+
+// leaf stores the value of the highest priority source that reports one,
+// recording its provenance and any conflict.
+func (m *merger) leaf(dst reflect.Value, path string, omitEmpty bool, values []sourceValue) {
+	var reported []sourceValue
+	for _, sv := range m.ranked(path, values) {
+		if !omitEmpty || !isEmpty(sv.value) {
+			reported = append(reported, sv)
+		}
+	}
+	if len(reported) == 0 {
+		return
+	}
+
+	chosen := reported[0]
+	source := m.sources[chosen.source]
+	setValue(dst, chosen.value)
+	m.result.Provenance[path] = Provenance{Source: source.Name, Time: source.Time}
+
+	conflict := MergeConflict{Field: path, Chosen: source.Name}
+	agree := true
+	for _, sv := range reported {
+		if !reflect.DeepEqual(sv.value.Interface(), chosen.value.Interface()) {
+			agree = false
+		}
+		conflict.Values = append(conflict.Values, ConflictValue{Source: m.sources[sv.source].Name, Value: format(sv.value)})
+	}
+	if !agree {
+		m.result.Conflicts = append(m.result.Conflicts, conflict)
+	}
+}
+
+// ranked returns values ordered by the priority of their source for path,
+// then by time, most recent first.
+func (m *merger) ranked(path string, values []sourceValue) []sourceValue {
+	rank := m.policy.rank(path)
+	ranked := append([]sourceValue(nil), values...)
+	sort.SliceStable(ranked, func(a, b int) bool {
+		sa, sb := m.sources[ranked[a].source], m.sources[ranked[b].source]
+		if ra, rb := rank(sa.Name), rank(sb.Name); ra != rb {
+			return ra < rb
+		}
+		return sa.Time.After(sb.Time)
+	})
+	return ranked
+}
+
+// rank returns a function giving the position of a source in the priority
+// list that applies to path.
+func (p MergePolicy) rank(path string) func(string) int {
+	priority := p.Priority
+	for prefix := FieldPath(path); prefix != ""; {
+		if fields, ok := p.Fields[prefix]; ok {
+			priority = fields
+			break
+		}
+		i := strings.LastIndex(prefix, ".")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return func(source string) int {
+		for i, name := range priority {
+			if name == source {
+				return i
+			}
+		}
+		return len(priority)
+	}
+}
+
+// omits reports whether a field's tag omits it from JSON when empty.
+func omits(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty reports whether a value would be omitted by omitempty or omitzero.
+// Types with an IsZero method, such as Optional, decide for themselves.
+func isEmpty(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// setValue stores a copy of value in v.
+func setValue(v, value reflect.Value) {
+	if value.Kind() == reflect.Slice && !value.IsNil() {
+		value = reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value)
+	}
+	v.Set(value)
+}
@@ -0,0 +1,229 @@
+package schemas
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	mergeEarlier = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mergeLater   = mergeEarlier.Add(time.Hour)
+)
+
+func TestMergeInventoryPriority(t *testing.T) {
+	redfish := InventorySource{Name: "redfish", Time: mergeEarlier, Detail: InventoryDetail{
+		UUID:        "4c4c4544-0042-3610-8052-b3c04f333333",
+		BiosVersion: "1.10.2",
+		Serial:      "CN123",
+		Chassis:     &ChassisDetail{Serial: "CH1", Type: "RackMount"},
+	}}
+	agent := InventorySource{Name: "agent", Time: mergeLater, Detail: InventoryDetail{
+		UUID:        "4C4C4544-0042-3610-8052-B3C04F333333",
+		BiosVersion: "1.11.0",
+		Model:       "PowerEdge R650",
+		Chassis:     &ChassisDetail{Serial: "CH2"},
+	}}
+	csv := InventorySource{Name: "csv", Time: mergeEarlier, Detail: InventoryDetail{Serial: "CN999"}}
+
+	tests := []struct {
+		name       string
+		policy     MergePolicy
+		bios       string
+		serial     string
+		chassis    string
+		provenance map[string]string
+	}{
+		{
+			name:    "priority order",
+			policy:  MergePolicy{Priority: []string{"redfish", "agent"}},
+			bios:    "1.10.2",
+			serial:  "CN123",
+			chassis: "CH1",
+			provenance: map[string]string{
+				"bios_version": "redfish", "model": "agent", "serial": "redfish", "chassis.serial": "redfish", "chassis.type": "redfish",
+			},
+		},
+		{
+			name:    "unlisted sources rank last, most recent first",
+			policy:  MergePolicy{},
+			bios:    "1.11.0",
+			serial:  "CN123",
+			chassis: "CH2",
+			provenance: map[string]string{
+				"bios_version": "agent", "serial": "redfish", "chassis.serial": "agent",
+			},
+		},
+		{
+			name: "field overrides",
+			policy: MergePolicy{
+				Priority: []string{"redfish", "agent", "csv"},
+				Fields:   map[string][]string{"bios_version": {"agent"}, "serial": {"csv"}, "chassis": {"agent", "redfish"}},
+			},
+			bios:    "1.11.0",
+			serial:  "CN999",
+			chassis: "CH2",
+			provenance: map[string]string{
+				"bios_version": "agent", "serial": "csv", "chassis.serial": "agent", "chassis.type": "redfish",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeInventory(tt.policy, redfish, agent, csv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := merged.Detail
+			if d.BiosVersion != tt.bios || d.Serial != tt.serial || d.Chassis == nil || d.Chassis.Serial != tt.chassis || d.Model != "PowerEdge R650" {
+				t.Errorf("merged bios %s serial %s chassis %+v model %s", d.BiosVersion, d.Serial, d.Chassis, d.Model)
+			}
+			for path, source := range tt.provenance {
+				if got := merged.Provenance[path].Source; got != source {
+					t.Errorf("provenance of %s = %q, want %q", path, got, source)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeInventoryConflicts(t *testing.T) {
+	a := InventorySource{Name: "a", Detail: InventoryDetail{Serial: "CN123", Model: "R650", Manufacturer: "Dell Inc."}}
+	b := InventorySource{Name: "b", Detail: InventoryDetail{Serial: "CN456", Model: "R650"}}
+	merged, err := MergeInventory(MergePolicy{Priority: []string{"a", "b"}}, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MergeConflict{{
+		Field:  "serial",
+		Chosen: "a",
+		Values: []ConflictValue{{Source: "a", Value: "CN123"}, {Source: "b", Value: "CN456"}},
+	}}
+	if !reflect.DeepEqual(merged.Conflicts, want) {
+		t.Errorf("Conflicts = %+v, want %+v", merged.Conflicts, want)
+	}
+}
+
+func TestMergeInventoryUUIDMismatch(t *testing.T) {
+	a := InventorySource{Name: "a", Detail: InventoryDetail{UUID: "4c4c4544-0042-3610-8052-b3c04f333333"}}
+	b := InventorySource{Name: "b", Detail: InventoryDetail{}}
+	c := InventorySource{Name: "c", Detail: InventoryDetail{UUID: "38393350-3830-5a43-3233-313230334a4b"}}
+	if _, err := MergeInventory(MergePolicy{}, a, b, c); err == nil {
+		t.Error("MergeInventory() of sources with different UUIDs succeeded")
+	}
+	if merged, err := MergeInventory(MergePolicy{}, a, b); err != nil || merged.Detail.UUID != a.Detail.UUID {
+		t.Errorf("MergeInventory() = %+v, %v", merged.Detail, err)
+	}
+}
+
+func TestMergeInventoryElements(t *testing.T) {
+	redfish := InventorySource{Name: "redfish", Time: mergeEarlier, Detail: InventoryDetail{
+		EthernetInterfaces: []EthernetInterface{
+			{MAC: MustParseMACAddress("b0:7b:25:c8:2a:10"), IP: MustParseIPAddress("10.1.0.12"), Enabled: Some(false), SpeedMbps: 0},
+		},
+		TrustedModules: []TrustedModule{{InterfaceType: "TPM2_0"}},
+	}}
+	agent := InventorySource{Name: "agent", Time: mergeLater, Detail: InventoryDetail{
+		EthernetInterfaces: []EthernetInterface{
+			{MAC: MustParseMACAddress("b0:7b:25:c8:2a:11"), IP: MustParseIPAddress("10.1.0.13")},
+			{MAC: MustParseMACAddress("B0-7B-25-C8-2A-10"), IP: MustParseIPAddress("10.1.0.99"), Enabled: Some(true), SpeedMbps: 25000, Name: "eth0"},
+		},
+		TrustedModules: []TrustedModule{{InterfaceType: "TPM2_0", Firmware: "7.2"}, {InterfaceType: "TPM1_2"}},
+	}}
+	policy := MergePolicy{
+		Priority: []string{"redfish", "agent"},
+		Fields:   map[string][]string{"ethernet_interfaces.name": {"agent"}},
+	}
+	merged, err := MergeInventory(policy, redfish, agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ifaces := merged.Detail.EthernetInterfaces
+	if len(ifaces) != 2 {
+		t.Fatalf("EthernetInterfaces = %+v, want the union of both sources", ifaces)
+	}
+	e := ifaces[0]
+	if e.MAC != MustParseMACAddress("b0:7b:25:c8:2a:10") || e.IP != MustParseIPAddress("10.1.0.12") || e.Name != "eth0" || e.SpeedMbps != 25000 {
+		t.Errorf("merged interface = %+v", e)
+	}
+	// false is a report; an unreported plain 0 is not.
+	if enabled, ok := e.Enabled.Get(); !ok || enabled {
+		t.Errorf("Enabled = %v, want false from redfish", e.Enabled)
+	}
+	if ifaces[1].IP != MustParseIPAddress("10.1.0.13") {
+		t.Errorf("second interface = %+v", ifaces[1])
+	}
+
+	provenance := map[string]string{
+		"ethernet_interfaces[b0:7b:25:c8:2a:10].ip":         "redfish",
+		"ethernet_interfaces[b0:7b:25:c8:2a:10].enabled":    "redfish",
+		"ethernet_interfaces[b0:7b:25:c8:2a:10].speed_mbps": "agent",
+		"ethernet_interfaces[b0:7b:25:c8:2a:10].name":       "agent",
+		"ethernet_interfaces[b0:7b:25:c8:2a:11].ip":         "agent",
+		"trusted_modules": "redfish",
+	}
+	for path, source := range provenance {
+		if got := merged.Provenance[path].Source; got != source {
+			t.Errorf("provenance of %s = %q, want %q", path, got, source)
+		}
+	}
+	if _, ok := merged.Provenance["ethernet_interfaces[b0:7b:25:c8:2a:10].mac"]; !ok {
+		t.Error("no provenance for the MAC of a merged interface")
+	}
+
+	// Trusted modules have no identity, so the list is taken whole.
+	if tm := merged.Detail.TrustedModules; len(tm) != 1 || tm[0].Firmware != "" {
+		t.Errorf("TrustedModules = %+v, want redfish's list", tm)
+	}
+
+	var conflicts []string
+	for _, c := range merged.Conflicts {
+		conflicts = append(conflicts, c.Field)
+	}
+	want := []string{
+		"ethernet_interfaces[b0:7b:25:c8:2a:10].ip",
+		"ethernet_interfaces[b0:7b:25:c8:2a:10].enabled",
+		"trusted_modules",
+	}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", conflicts, want)
+	}
+}
+
+func TestMergeInventoryElementsBySecondKey(t *testing.T) {
+	// The agent does not see MACs, so its interfaces match redfish's by URI.
+	redfish := InventorySource{Name: "redfish", Time: mergeEarlier, Detail: InventoryDetail{
+		EthernetInterfaces: []EthernetInterface{
+			{MAC: MustParseMACAddress("b0:7b:25:c8:2a:10"), URI: "/EthernetInterfaces/1", SpeedMbps: 25000},
+			{MAC: MustParseMACAddress("b0:7b:25:c8:2a:11"), URI: "/EthernetInterfaces/2"},
+		},
+	}}
+	agent := InventorySource{Name: "agent", Time: mergeLater, Detail: InventoryDetail{
+		EthernetInterfaces: []EthernetInterface{
+			{URI: "/EthernetInterfaces/2", Name: "eth1"},
+			{URI: "/EthernetInterfaces/1", Name: "eth0"},
+		},
+	}}
+	merged, err := MergeInventory(MergePolicy{Priority: []string{"redfish", "agent"}}, redfish, agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifaces := merged.Detail.EthernetInterfaces
+	if len(ifaces) != 2 || ifaces[0].Name != "eth0" || ifaces[0].SpeedMbps != 25000 || ifaces[1].Name != "eth1" {
+		t.Fatalf("EthernetInterfaces = %+v, want redfish's interfaces named by the agent", ifaces)
+	}
+	if got := merged.Provenance["ethernet_interfaces[b0:7b:25:c8:2a:11].name"].Source; got != "agent" {
+		t.Errorf("provenance of the second name = %q, want agent", got)
+	}
+
+	// Two elements of one source matching the same element are ambiguous.
+	agent.Detail.EthernetInterfaces[0].URI = "/EthernetInterfaces/1"
+	merged, err = MergeInventory(MergePolicy{Priority: []string{"redfish", "agent"}}, redfish, agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifaces := merged.Detail.EthernetInterfaces; len(ifaces) != 2 || ifaces[0].Name != "" {
+		t.Errorf("EthernetInterfaces = %+v, want redfish's list whole", ifaces)
+	}
+}
@@ -0,0 +1,205 @@
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Identification of the inventory request and response in their envelope
+// headers.  Version 2 nests the chassis in InventoryDetail.Chassis, where
+// version 1 used the flat Chassis_* fields.  Requests are accepted from any
+// Version with a supported major version.
+const (
+	InventoryRequestSchemaID  = "InventoryDetailRequest"
+	InventoryResponseSchemaID = "InventoryDetailResponse"
+	InventorySchemaVersionV1  = "1.0.0"
+	InventorySchemaVersion    = "2.0.0"
+)
+
+var inventoryMajorVersions = []string{majorVersion(InventorySchemaVersionV1), majorVersion(InventorySchemaVersion)}
+
+// MaxInventoryDetails is the largest number of items accepted in one request.
+const MaxInventoryDetails = 1000
+
+// InventoryRequest submits the inventory of one or more nodes.  The items are
+// carried in InventoryDetailArray; Header.Payload must be absent.
+type InventoryRequest struct {
+	Header               Envelope          `json:"header"`
+	InventoryDetailArray []InventoryDetail `json:"inventory_detail_array" jsonschema:"maxItems=1000"`
+}
+
+// NewInventoryRequest creates a request with a current header.
+func NewInventoryRequest(details []InventoryDetail) InventoryRequest {
+	return InventoryRequest{
+		Header:               Envelope{SchemaID: InventoryRequestSchemaID, Version: InventorySchemaVersion},
+		InventoryDetailArray: details,
+	}
+}
+
+// ValidateHeader checks that the header names this schema and a compatible
+// version, and carries no payload of its own.
+func (r InventoryRequest) ValidateHeader() error {
+	if r.Header.SchemaID != InventoryRequestSchemaID {
+		return fmt.Errorf("schema_id %q does not match %q", r.Header.SchemaID, InventoryRequestSchemaID)
+	}
+	if r.Header.Payload != nil {
+		return errors.New("header payload is not used, items belong in inventory_detail_array")
+	}
+	major := majorVersion(r.Header.Version)
+	for _, supported := range inventoryMajorVersions {
+		if major == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("version %q is not compatible with %s", r.Header.Version, InventorySchemaVersion)
+}
+
+// IsV1 reports whether the request uses the version 1 shape.
+func (r InventoryRequest) IsV1() bool {
+	return majorVersion(r.Header.Version) == majorVersion(InventorySchemaVersionV1)
+}
+
+// ToV2 converts the request and its items to the current version.
+func (r InventoryRequest) ToV2() InventoryRequest {
+	return r.convert(InventorySchemaVersion, InventoryDetail.ToV2)
+}
+
+// ToV1 converts the request and its items to version 1 for consumers that
+// have not moved to the nested chassis.
+func (r InventoryRequest) ToV1() InventoryRequest {
+	return r.convert(InventorySchemaVersionV1, InventoryDetail.ToV1)
+}
+
+// MarshalJSON writes the items of a version 1 request with MarshalV1, so that
+// its trusted modules and components are the strings version 1 consumers
+// expect.
+func (r InventoryRequest) MarshalJSON() ([]byte, error) {
+	type plain InventoryRequest
+	if !r.IsV1() {
+		return json.Marshal(plain(r))
+	}
+	details := make([]json.RawMessage, len(r.InventoryDetailArray))
+	for i, d := range r.InventoryDetailArray {
+		b, err := d.MarshalV1()
+		if err != nil {
+			return nil, err
+		}
+		details[i] = b
+	}
+	return json.Marshal(struct {
+		Header               Envelope          `json:"header"`
+		InventoryDetailArray []json.RawMessage `json:"inventory_detail_array"`
+	}{r.Header, details})
+}
+
+func (r InventoryRequest) convert(version string, fn func(InventoryDetail) InventoryDetail) InventoryRequest {
+	details := make([]InventoryDetail, len(r.InventoryDetailArray))
+	for i, d := range r.InventoryDetailArray {
+		details[i] = fn(d)
+	}
+	r.Header.Version = version
+	r.InventoryDetailArray = details
+	return r
+}
+
+func majorVersion(v string) string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), ".")
+	return major
+}
+
+// Validate checks the header and the number of items.  Problems with
+// individual items are reported by Process instead.
+func (r InventoryRequest) Validate() error {
+	var errs []error
+	if err := r.ValidateHeader(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(r.InventoryDetailArray) == 0 {
+		errs = append(errs, errors.New("inventory_detail_array is empty"))
+	}
+	if len(r.InventoryDetailArray) > MaxInventoryDetails {
+		errs = append(errs, fmt.Errorf("inventory_detail_array has %d items, more than the limit of %d", len(r.InventoryDetailArray), MaxInventoryDetails))
+	}
+	return errors.Join(errs...)
+}
+
+// InventoryItemStatus is the outcome for a single item of a request.
+type InventoryItemStatus string
+
+const (
+	InventoryAccepted  InventoryItemStatus = "Accepted"  // The item will be stored
+	InventoryRejected  InventoryItemStatus = "Rejected"  // The item is invalid
+	InventoryDuplicate InventoryItemStatus = "Duplicate" // An earlier item has the same UUID or serial
+)
+
+// InventoryItemResult reports what happened to one item, identified by its
+// position in inventory_detail_array.
+type InventoryItemResult struct {
+	Index  int                 `json:"index"`
+	UUID   string              `json:"uuid,omitempty"`
+	Serial string              `json:"serial,omitempty"`
+	Status InventoryItemStatus `json:"status" jsonschema:"enum=Accepted,enum=Rejected,enum=Duplicate"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// InventoryResponse reports the per-item results of an InventoryRequest,
+// with a count for each status.
+type InventoryResponse struct {
+	Header     Envelope              `json:"header"`
+	Results    []InventoryItemResult `json:"results"`
+	Accepted   int                   `json:"accepted"`
+	Rejected   int                   `json:"rejected"`
+	Duplicates int                   `json:"duplicates"`
+}
+
+// Process validates the request and decides which items to accept.  Items
+// without a UUID or serial number are rejected, and an item sharing its UUID
+// or serial with an earlier one is reported as a Duplicate.  The accepted
+// items are returned in request order, converted to version 2.  An error is
+// returned, with no results, if the request as a whole is invalid.
+func (r InventoryRequest) Process() ([]InventoryDetail, InventoryResponse, error) {
+	resp := InventoryResponse{
+		Header: Envelope{SchemaID: InventoryResponseSchemaID, Version: InventorySchemaVersion},
+	}
+	if err := r.Validate(); err != nil {
+		return nil, resp, err
+	}
+
+	var accepted []InventoryDetail
+	uuids := map[string]int{}
+	serials := map[string]int{}
+	for i, d := range r.InventoryDetailArray {
+		result := InventoryItemResult{Index: i, UUID: d.UUID, Serial: d.Serial, Status: InventoryAccepted}
+		uuid, serial := strings.ToLower(d.UUID), d.Serial
+		if first, ok := uuids[uuid]; ok && uuid != "" {
+			result.Status = InventoryDuplicate
+			result.Error = fmt.Sprintf("uuid duplicates item %d", first)
+		} else if first, ok := serials[serial]; ok && serial != "" {
+			result.Status = InventoryDuplicate
+			result.Error = fmt.Sprintf("serial duplicates item %d", first)
+		} else if uuid == "" && serial == "" {
+			result.Status = InventoryRejected
+			result.Error = "item has neither a uuid nor a serial"
+		}
+
+		switch result.Status {
+		case InventoryAccepted:
+			if uuid != "" {
+				uuids[uuid] = i
+			}
+			if serial != "" {
+				serials[serial] = i
+			}
+			accepted = append(accepted, d.ToV2())
+			resp.Accepted++
+		case InventoryDuplicate:
+			resp.Duplicates++
+		default:
+			resp.Rejected++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return accepted, resp, nil
+}
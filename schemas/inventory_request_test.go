@@ -0,0 +1,128 @@
+package schemas
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInventoryRequestValidateHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"current", `{"schema_id": "InventoryDetailRequest", "version": "2.0.0"}`, true},
+		{"version 1", `{"schema_id": "InventoryDetailRequest", "version": "1.2.0"}`, true},
+		{"v prefix", `{"schema_id": "InventoryDetailRequest", "version": "v2.1.0"}`, true},
+		{"null payload", `{"schema_id": "InventoryDetailRequest", "version": "2.0.0", "payload": null}`, true},
+		{"wrong schema", `{"schema_id": "InventoryDetailResponse", "version": "2.0.0"}`, false},
+		{"unsupported version", `{"schema_id": "InventoryDetailRequest", "version": "3.0.0"}`, false},
+		{"payload", `{"schema_id": "InventoryDetailRequest", "version": "2.0.0", "payload": {"uuid": "a"}}`, false},
+		{"empty payload", `{"schema_id": "InventoryDetailRequest", "version": "2.0.0", "payload": []}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r InventoryRequest
+			if err := json.Unmarshal([]byte(`{"header": `+tt.header+`}`), &r); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.ValidateHeader(); (err == nil) != tt.ok {
+				t.Errorf("ValidateHeader() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestInventoryRequestValidate(t *testing.T) {
+	if err := NewInventoryRequest(nil).Validate(); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("Validate() of an empty request = %v", err)
+	}
+	details := make([]InventoryDetail, MaxInventoryDetails+1)
+	if err := NewInventoryRequest(details).Validate(); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Errorf("Validate() of %d items = %v", len(details), err)
+	}
+	if err := NewInventoryRequest(details[:MaxInventoryDetails]).Validate(); err != nil {
+		t.Errorf("Validate() of %d items = %v", MaxInventoryDetails, err)
+	}
+}
+
+func TestInventoryRequestProcess(t *testing.T) {
+	r := NewInventoryRequest([]InventoryDetail{
+		{UUID: "4C4C4544-0042-3610-8052-B3C04F333333"},
+		{Serial: "CN123"},
+		{},
+		{UUID: "4c4c4544-0042-3610-8052-b3c04f333333"},
+		{UUID: "38393350-3830-5a43-3233-313230334a4b", Serial: "CN123"},
+		{UUID: "38393350-3830-5a43-3233-313230334a4b", Serial: "CN456"},
+	})
+	accepted, resp, err := r.Process()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []InventoryItemStatus{
+		InventoryAccepted,
+		InventoryAccepted,
+		InventoryRejected,
+		InventoryDuplicate,
+		InventoryDuplicate,
+		InventoryAccepted,
+	}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(resp.Results), len(want))
+	}
+	for i, status := range want {
+		if got := resp.Results[i]; got.Index != i || got.Status != status {
+			t.Errorf("result %d = %+v, want %s", i, got, status)
+		}
+	}
+	if resp.Accepted != 3 || resp.Rejected != 1 || resp.Duplicates != 2 {
+		t.Errorf("counts accepted %d rejected %d duplicates %d, want 3, 1 and 2", resp.Accepted, resp.Rejected, resp.Duplicates)
+	}
+	if len(accepted) != 3 || accepted[2].Serial != "CN456" {
+		t.Errorf("accepted = %+v", accepted)
+	}
+	if resp.Header.SchemaID != InventoryResponseSchemaID || resp.Header.Version != InventorySchemaVersion {
+		t.Errorf("response header = %+v", resp.Header)
+	}
+
+	r.Header.Payload = map[string]interface{}{}
+	if accepted, resp, err := r.Process(); err == nil || accepted != nil || resp.Results != nil {
+		t.Errorf("Process() with a header payload = %v, %v, %v, want an error and no results", accepted, resp, err)
+	}
+}
+
+func TestInventoryRequestMarshalV1(t *testing.T) {
+	r := NewInventoryRequest([]InventoryDetail{{
+		Serial:         "CN123",
+		Chassis:        &ChassisDetail{Serial: "CH1"},
+		TrustedModules: []TrustedModule{{InterfaceType: "TPM2_0", Firmware: "7.2"}},
+	}})
+	tests := []struct {
+		name string
+		r    InventoryRequest
+		want string
+	}{
+		{"version 2", r, `{"serial":"CN123","trusted_modules":[{"interface_type":"TPM2_0","firmware":"7.2"}],"chassis":{"serial":"CH1"}}`},
+		{"version 1", r.ToV1(), `{"serial":"CN123","chassis_serial":"CH1","trusted_modules":["TPM2_0"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out struct {
+				Header               Envelope          `json:"header"`
+				InventoryDetailArray []json.RawMessage `json:"inventory_detail_array"`
+			}
+			if err := json.Unmarshal(b, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Header != tt.r.Header || len(out.InventoryDetailArray) != 1 || string(out.InventoryDetailArray[0]) != tt.want {
+				t.Errorf("Marshal() = %s, want the item %s", b, tt.want)
+			}
+		})
+	}
+}
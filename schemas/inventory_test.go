@@ -0,0 +1,165 @@
+package schemas
+
+import (
+	"encoding/json"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestInventoryTotals(t *testing.T) {
+	d := InventoryDetail{
+		MemoryModules: []MemoryModule{{Capacity: 32 * GiB}, {Capacity: 32 * GiB}, {}},
+		Drives:        []Drive{{Capacity: 480 * GB}, {Capacity: 1920 * GB}},
+	}
+	if got := d.TotalMemory(); got != 64*GiB {
+		t.Errorf("TotalMemory() = %s, want 64GiB", got)
+	}
+	if got := d.TotalDriveCapacity(); got != 2400*GB {
+		t.Errorf("TotalDriveCapacity() = %s, want 2.4TB", got)
+	}
+	if got := (InventoryDetail{}).TotalMemory(); got != 0 {
+		t.Errorf("TotalMemory() of no modules = %s", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    InventoryDetail
+		count int
+		typ   string
+		mem   ByteQuantity
+	}{
+		{
+			name:  "from sub-inventory",
+			in:    InventoryDetail{Processors: []Processor{{Model: "Xeon 6430"}, {Model: "Xeon 6430"}}, MemoryModules: []MemoryModule{{Capacity: 32 * GiB}, {Capacity: 32 * GiB}}},
+			count: 2, typ: "Xeon 6430", mem: 64 * GiB,
+		},
+		{
+			name:  "summary fields kept",
+			in:    InventoryDetail{ProcessorCount: 4, ProcessorType: "EPYC", Memory: 128 * GiB, Processors: []Processor{{Model: "Xeon"}}, MemoryModules: []MemoryModule{{Capacity: GiB}}},
+			count: 4, typ: "EPYC", mem: 128 * GiB,
+		},
+		{
+			name: "version 1 memory total kept",
+			in:   InventoryDetail{MemoryTotal: 64, MemoryModules: []MemoryModule{{Capacity: GiB}}},
+		},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.in
+			d.Summarize()
+			if d.ProcessorCount != tt.count || d.ProcessorType != tt.typ || d.Memory != tt.mem {
+				t.Errorf("Summarize() = count %d type %q memory %s, want %d %q %s", d.ProcessorCount, d.ProcessorType, d.Memory, tt.count, tt.typ, tt.mem)
+			}
+		})
+	}
+}
+
+func TestSubInventoryJSON(t *testing.T) {
+	in := InventoryDetail{
+		UUID:          "4c4c4544-0042-3610-8052-b3c04f333333",
+		Processors:    []Processor{{Socket: "CPU 1", Architecture: "x86", TotalCores: 32, TotalThreads: 64, MaxSpeedMHz: 4000}},
+		MemoryModules: []MemoryModule{{Name: "DIMM A1", DeviceType: "DDR5", Capacity: 32 * GiB, SpeedMHz: 4800}},
+		Drives:        []Drive{{Name: "SSD 0", MediaType: "SSD", Protocol: "NVMe", Capacity: 960 * GB}},
+		Accelerators:  []Accelerator{{Type: "GPU", Model: "L4", Memory: 24 * GiB}},
+		PCIeDevices:   []PCIeDevice{{Name: "BCM57414", DeviceType: "MultiFunction"}},
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out InventoryDetail
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip through %s gave %+v", b, out)
+	}
+
+	var drives struct {
+		Drives []map[string]interface{} `json:"drives"`
+	}
+	if err := json.Unmarshal(b, &drives); err != nil {
+		t.Fatal(err)
+	}
+	if got := drives.Drives[0]["capacity"]; got != "960GB" {
+		t.Errorf("drive capacity marshaled as %v, want 960GB", got)
+	}
+}
+
+func TestEthernetInterfaceIsUp(t *testing.T) {
+	tests := []struct {
+		e    EthernetInterface
+		want bool
+	}{
+		{EthernetInterface{}, true},
+		{EthernetInterface{Enabled: Some(true), LinkStatus: LinkUp}, true},
+		{EthernetInterface{Enabled: Some(false), LinkStatus: LinkUp}, false},
+		{EthernetInterface{LinkStatus: LinkDown}, false},
+		{EthernetInterface{LinkStatus: NoLink}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.e.IsUp(); got != tt.want {
+			t.Errorf("IsUp() of enabled %v link %q = %v, want %v", tt.e.Enabled, tt.e.LinkStatus, got, tt.want)
+		}
+	}
+}
+
+func TestAllAddresses(t *testing.T) {
+	e := EthernetInterface{
+		IP:        MustParseIPAddress("10.1.0.12"),
+		Addresses: []InterfaceAddress{{Address: MustParseIPAddress("10.1.0.12")}, {Address: MustParseIPAddress("fd00::12")}, {}},
+	}
+	got := e.AllAddresses()
+	if !reflect.DeepEqual(got, []IPAddress{MustParseIPAddress("10.1.0.12"), MustParseIPAddress("fd00::12")}) {
+		t.Errorf("AllAddresses() = %v", got)
+	}
+	if got := (EthernetInterface{}).AllAddresses(); got != nil {
+		t.Errorf("AllAddresses() of no addresses = %v", got)
+	}
+	if got := (EthernetInterface{IP: MustParseIPAddress("fd00::12"), Addresses: []InterfaceAddress{{Address: MustParseIPAddress("FD00:0::12")}}}).AllAddresses(); len(got) != 1 {
+		t.Errorf("AllAddresses() listed the same address twice: %v", got)
+	}
+}
+
+func TestInventoryAdapter(t *testing.T) {
+	d := InventoryDetail{NetworkAdapters: []NetworkAdapter{{ID: "NIC.Slot.1", Serial: "MT1234"}, {Serial: "NOID"}}}
+	if a, ok := d.Adapter("NIC.Slot.1"); !ok || a.Serial != "MT1234" {
+		t.Errorf("Adapter(NIC.Slot.1) = %+v, %v", a, ok)
+	}
+	for _, id := range []string{"", "NIC.Slot.2"} {
+		if a, ok := d.Adapter(id); ok {
+			t.Errorf("Adapter(%q) = %+v", id, a)
+		}
+	}
+}
+
+func TestManagementInterface(t *testing.T) {
+	d := InventoryDetail{EthernetInterfaces: []EthernetInterface{
+		{Name: "down", IP: MustParseIPAddress("10.254.1.10"), LinkStatus: LinkDown},
+		{Name: "v6", IP: MustParseIPAddress("fd00::12")},
+		{Name: "hsn", IP: MustParseIPAddress("10.150.0.12")},
+		{Name: "mgmt", Addresses: []InterfaceAddress{{Address: MustParseIPAddress("10.254.1.12"), PrefixLength: 17}}},
+	}}
+	tests := []struct {
+		name     string
+		networks []netip.Prefix
+		want     string
+	}{
+		{"first IPv4 interface that is up", nil, "hsn"},
+		{"on the management network", []netip.Prefix{netip.MustParsePrefix("10.254.0.0/17")}, "mgmt"},
+		{"IPv6 network", []netip.Prefix{netip.MustParsePrefix("fd00::/64")}, "v6"},
+		{"no match", []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := d.ManagementInterface(tt.networks...)
+			if e.Name != tt.want || ok != (tt.want != "") {
+				t.Errorf("ManagementInterface() = %q, %v, want %q", e.Name, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToV2 converts a detail in the version 1 shape to the version 2 shape:
+//
+//   - the flat Chassis_* fields move to a nested Chassis
+//   - MemoryTotal in GiB becomes the Memory ByteQuantity
+//   - adapters embedded in NetworkInterfaces move to NetworkAdapters and are
+//     referenced by AdapterID
+//
+// Version 2 fields that are already set are kept and the version 1 fields are
+// only cleared.
+func (d InventoryDetail) ToV2() InventoryDetail {
+	if d.Chassis == nil {
+		c := ChassisDetail{
+			SKU:          d.Chassis_SKU,
+			Serial:       d.Chassis_Serial,
+			AssetTag:     d.Chassis_AssetTag,
+			Manufacturer: d.Chassis_Manufacturer,
+			Model:        d.Chassis_Model,
+		}
+		if !c.IsZero() {
+			d.Chassis = &c
+		}
+	}
+	d.Chassis_SKU, d.Chassis_Serial, d.Chassis_AssetTag, d.Chassis_Manufacturer, d.Chassis_Model = "", "", "", "", ""
+
+	if d.Memory == 0 {
+		d.Memory = ByteQuantityFromFloat(float64(d.MemoryTotal), GiB)
+	}
+	d.MemoryTotal = 0
+
+	interfaces := make([]NetworkInterface, len(d.NetworkInterfaces))
+	d.NetworkAdapters = append([]NetworkAdapter(nil), d.NetworkAdapters...)
+	for i, n := range d.NetworkInterfaces {
+		if n.Adapter != nil && n.AdapterID == "" {
+			n.AdapterID = d.addAdapter(*n.Adapter)
+		}
+		n.Adapter = nil
+		interfaces[i] = n
+	}
+	if d.NetworkInterfaces != nil {
+		d.NetworkInterfaces = interfaces
+	}
+	return d
+}
+
+// addAdapter adds a to NetworkAdapters, unless an adapter with the same URI
+// or serial number is already there, and returns its ID.  Adapters without an
+// ID are given their URI, or failing that a generated ID.
+func (d *InventoryDetail) addAdapter(a NetworkAdapter) string {
+	for _, existing := range d.NetworkAdapters {
+		if a.URI != "" && existing.URI == a.URI || a.Serial != "" && existing.Serial == a.Serial {
+			return existing.ID
+		}
+	}
+	if a.ID == "" {
+		a.ID = a.URI
+	}
+	for n := len(d.NetworkAdapters); a.ID == "" || d.hasAdapter(a.ID); n++ {
+		a.ID = fmt.Sprintf("adapter%d", n)
+	}
+	d.NetworkAdapters = append(d.NetworkAdapters, a)
+	return a.ID
+}
+
+func (d InventoryDetail) hasAdapter(id string) bool {
+	_, ok := d.Adapter(id)
+	return ok
+}
+
+// ToV1 converts a detail in the version 2 shape to the version 1 shape for
+// consumers that only read the flat Chassis_* fields, MemoryTotal and
+// embedded adapters.  Only the immediate chassis can be represented, so its
+// URI, Type and parents are dropped, as are adapters no interface refers to.
+// Trusted modules and components are left as objects; MarshalV1 writes them
+// as the strings version 1 listed.
+func (d InventoryDetail) ToV1() InventoryDetail {
+	if d.Chassis != nil {
+		d.Chassis_SKU = d.Chassis.SKU
+		d.Chassis_Serial = d.Chassis.Serial
+		d.Chassis_AssetTag = d.Chassis.AssetTag
+		d.Chassis_Manufacturer = d.Chassis.Manufacturer
+		d.Chassis_Model = d.Chassis.Model
+		d.Chassis = nil
+	}
+
+	if d.Memory != 0 {
+		d.MemoryTotal = float32(d.Memory.Float(GiB))
+		d.Memory = 0
+	}
+
+	if d.NetworkInterfaces != nil {
+		interfaces := make([]NetworkInterface, len(d.NetworkInterfaces))
+		for i, n := range d.NetworkInterfaces {
+			if a, ok := d.Adapter(n.AdapterID); ok && n.Adapter == nil {
+				n.Adapter = &a
+			}
+			n.AdapterID = ""
+			interfaces[i] = n
+		}
+		d.NetworkInterfaces = interfaces
+	}
+	d.NetworkAdapters = nil
+	return d
+}
+
+// MarshalV1 marshals d in the version 1 shape: ToV1 is applied, and trusted
+// modules and components are written as their interface type and URI
+// strings, dropping the rest of each along with any without one.
+func (d InventoryDetail) MarshalV1() ([]byte, error) {
+	type plain InventoryDetail
+	v1 := struct {
+		plain
+		TrustedModules    []string `json:"trusted_modules,omitempty"`
+		TrustedComponents []string `json:"trusted_components,omitempty"`
+	}{plain: plain(d.ToV1())}
+	for _, m := range d.TrustedModules {
+		if m.InterfaceType != "" {
+			v1.TrustedModules = append(v1.TrustedModules, m.InterfaceType)
+		}
+	}
+	for _, c := range d.TrustedComponents {
+		if c.URI != "" {
+			v1.TrustedComponents = append(v1.TrustedComponents, c.URI)
+		}
+	}
+	return json.Marshal(v1)
+}
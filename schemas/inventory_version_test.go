@@ -0,0 +1,121 @@
+package schemas
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInventoryToV2(t *testing.T) {
+	v1 := `{
+		"uuid": "4c4c4544-0042-3610-8052-b3c04f333333",
+		"memory_total": 64,
+		"chassis_serial": "CN123",
+		"chassis_sku": "R650",
+		"network_interfaces": [
+			{"uri": "/redfish/v1/Systems/1/NetworkInterfaces/NIC.Slot.1", "adapter": {"uri": "/redfish/v1/Chassis/1/NetworkAdapters/NIC.Slot.1", "serial": "MT1234"}},
+			{"uri": "/redfish/v1/Systems/1/NetworkInterfaces/NIC.Slot.1-2", "adapter": {"serial": "MT1234"}},
+			{"uri": "/redfish/v1/Systems/1/NetworkInterfaces/NIC.Embedded.1"}
+		]
+	}`
+	var d InventoryDetail
+	if err := json.Unmarshal([]byte(v1), &d); err != nil {
+		t.Fatal(err)
+	}
+	v2 := d.ToV2()
+
+	if v2.Chassis == nil || v2.Chassis.Serial != "CN123" || v2.Chassis.SKU != "R650" || v2.Chassis_Serial != "" || v2.Chassis_SKU != "" {
+		t.Errorf("chassis %+v, flat serial %q", v2.Chassis, v2.Chassis_Serial)
+	}
+	if v2.Memory != 64*GiB || v2.MemoryTotal != 0 {
+		t.Errorf("Memory %s MemoryTotal %v, want 64GiB and 0", v2.Memory, v2.MemoryTotal)
+	}
+	if len(v2.NetworkAdapters) != 1 {
+		t.Fatalf("NetworkAdapters = %+v, want the shared adapter once", v2.NetworkAdapters)
+	}
+	id := v2.NetworkAdapters[0].ID
+	for i, n := range v2.NetworkInterfaces[:2] {
+		if n.Adapter != nil || n.AdapterID != id {
+			t.Errorf("interface %d = %+v, want AdapterID %s", i, n, id)
+		}
+	}
+	if n := v2.NetworkInterfaces[2]; n.AdapterID != "" {
+		t.Errorf("interface without an adapter got AdapterID %q", n.AdapterID)
+	}
+	if d.NetworkInterfaces[0].Adapter == nil {
+		t.Error("ToV2() modified the receiver's interfaces")
+	}
+
+	// Version 2 fields win over version 1 ones.
+	both := InventoryDetail{Chassis: &ChassisDetail{Serial: "V2"}, Chassis_Serial: "V1", Memory: 32 * GiB, MemoryTotal: 64}.ToV2()
+	if both.Chassis.Serial != "V2" || both.Memory != 32*GiB || both.Chassis_Serial != "" || both.MemoryTotal != 0 {
+		t.Errorf("ToV2() of both shapes = %+v", both)
+	}
+	if got := (InventoryDetail{}).ToV2(); got.Chassis != nil || got.NetworkInterfaces != nil {
+		t.Errorf("ToV2() of an empty detail = %+v", got)
+	}
+}
+
+func TestInventoryToV1(t *testing.T) {
+	v2 := InventoryDetail{
+		Memory:            48 * GiB,
+		Chassis:           &ChassisDetail{Type: "Blade", Serial: "BLADE1", Parent: &ChassisDetail{Serial: "ENC1"}},
+		NetworkAdapters:   []NetworkAdapter{{ID: "NIC.Slot.1", Serial: "MT1234"}, {ID: "unused"}},
+		NetworkInterfaces: []NetworkInterface{{Name: "NIC 1", AdapterID: "NIC.Slot.1"}, {Name: "NIC 2", AdapterID: "missing"}},
+	}
+	v1 := v2.ToV1()
+	if v1.Chassis != nil || v1.Chassis_Serial != "BLADE1" {
+		t.Errorf("Chassis %+v Chassis_Serial %q", v1.Chassis, v1.Chassis_Serial)
+	}
+	if v1.Memory != 0 || v1.MemoryTotal != 48 {
+		t.Errorf("Memory %s MemoryTotal %v", v1.Memory, v1.MemoryTotal)
+	}
+	if v1.NetworkAdapters != nil {
+		t.Errorf("NetworkAdapters = %+v, want them embedded", v1.NetworkAdapters)
+	}
+	if n := v1.NetworkInterfaces[0]; n.AdapterID != "" || n.Adapter == nil || n.Adapter.Serial != "MT1234" {
+		t.Errorf("interface 0 = %+v", n)
+	}
+	if n := v1.NetworkInterfaces[1]; n.AdapterID != "" || n.Adapter != nil {
+		t.Errorf("interface with a dangling AdapterID = %+v", n)
+	}
+
+	// Converting back recovers everything version 1 can carry.
+	back := v1.ToV2()
+	if back.Chassis == nil || back.Chassis.Serial != "BLADE1" || back.Memory != 48*GiB || len(back.NetworkAdapters) != 1 || back.NetworkInterfaces[0].AdapterID != "NIC.Slot.1" {
+		t.Errorf("ToV2() of ToV1() = %+v", back)
+	}
+}
+
+func TestInventoryMarshalV1(t *testing.T) {
+	v2 := InventoryDetail{
+		Serial:            "CN123",
+		Memory:            48 * GiB,
+		TrustedModules:    []TrustedModule{{InterfaceType: "TPM2_0", Firmware: "7.2"}, {Firmware: "no type"}},
+		TrustedComponents: []TrustedComponent{{URI: "/redfish/v1/Chassis/1/TrustedComponents/iLO", Type: "Integrated"}},
+	}
+	b, err := v2.MarshalV1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"serial":"CN123","memory_total":48,"trusted_modules":["TPM2_0"],"trusted_components":["/redfish/v1/Chassis/1/TrustedComponents/iLO"]}`
+	if string(b) != want {
+		t.Errorf("MarshalV1() = %s, want %s", b, want)
+	}
+
+	var v1 struct {
+		TrustedModules    []string `json:"trusted_modules"`
+		TrustedComponents []string `json:"trusted_components"`
+	}
+	if err := json.Unmarshal(b, &v1); err != nil {
+		t.Errorf("version 1 consumer cannot read %s: %v", b, err)
+	}
+
+	var back InventoryDetail
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.TrustedModules, []TrustedModule{{InterfaceType: "TPM2_0"}}) || back.TrustedComponents[0].URI != v2.TrustedComponents[0].URI {
+		t.Errorf("Unmarshal() of MarshalV1() = %+v", back)
+	}
+}
@@ -0,0 +1,214 @@
+package schemas
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/invopop/jsonschema"
+)
+
+// MACAddress is a 48-bit MAC address.  Addresses are parsed with
+// net.ParseMAC, so colon, hyphen and dot separated input is accepted, and are
+// always printed and marshaled in canonical lowercase colon-separated form.
+// The zero MACAddress is unset and marshals as an empty string; there is no
+// invalid state, so addresses can be compared with ==.
+type MACAddress struct {
+	addr  [6]byte
+	valid bool
+}
+
+// ParseMACAddress parses a MAC address.
+func ParseMACAddress(s string) (MACAddress, error) {
+	hw, err := net.ParseMAC(s)
+	if err != nil {
+		return MACAddress{}, fmt.Errorf("invalid MAC address %q", s)
+	}
+	m, ok := MACAddressFrom(hw)
+	if !ok {
+		return MACAddress{}, fmt.Errorf("MAC address %q is not 48 bits", s)
+	}
+	return m, nil
+}
+
+// MustParseMACAddress is like ParseMACAddress but panics if s does not
+// parse.  It is intended for tests and constants.
+func MustParseMACAddress(s string) MACAddress {
+	m, err := ParseMACAddress(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// MACAddressFrom converts a net.HardwareAddr.  It reports false, and returns
+// the zero MACAddress, if hw is not 48 bits.
+func MACAddressFrom(hw net.HardwareAddr) (MACAddress, bool) {
+	var m MACAddress
+	if len(hw) != len(m.addr) {
+		return MACAddress{}, false
+	}
+	copy(m.addr[:], hw)
+	m.valid = true
+	return m, true
+}
+
+// IsValid reports whether the address is set.
+func (m MACAddress) IsValid() bool {
+	return m.valid
+}
+
+// IsZero reports whether the address is unset, so that omitzero omits it.
+func (m MACAddress) IsZero() bool {
+	return !m.valid
+}
+
+// HardwareAddr returns the address as a net.HardwareAddr, or nil if it is
+// unset.
+func (m MACAddress) HardwareAddr() net.HardwareAddr {
+	if !m.valid {
+		return nil
+	}
+	return net.HardwareAddr(m.addr[:])
+}
+
+// String returns the canonical form of the address, or an empty string if it
+// is unset.
+func (m MACAddress) String() string {
+	return m.HardwareAddr().String()
+}
+
+// Equal reports whether m and o are the same address.
+func (m MACAddress) Equal(o MACAddress) bool {
+	return m == o
+}
+
+func (m MACAddress) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *MACAddress) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*m = MACAddress{}
+		return nil
+	}
+	parsed, err := ParseMACAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (MACAddress) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Title:       "MACAddress",
+		Description: "A MAC address in the standard colon-separated 6 byte hex format. Hyphen separators, dot-separated groups of four digits and upper case are accepted on input, but output is always lower case and colon-separated.",
+		Pattern:     `^(([0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}|([0-9A-Fa-f]{4}\.){2}[0-9A-Fa-f]{4})$`,
+		Examples:    []interface{}{"ae:12:e2:ff:89:9d"},
+	}
+}
+
+// IPAddress is an IPv4 or IPv6 address backed by a netip.Addr, printed and
+// marshaled in its canonical form (e.g. IPv6 zero compression in lower
+// case).  IPv6 zones are rejected, since they have no meaning off the host
+// that wrote them.  The zero IPAddress is unset and marshals as an empty
+// string; as with MACAddress, addresses can be compared with ==.
+type IPAddress struct {
+	addr netip.Addr
+}
+
+// ParseIPAddress parses an IP address.
+func ParseIPAddress(s string) (IPAddress, error) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return IPAddress{}, fmt.Errorf("invalid IP address %q", s)
+	}
+	if addr.Zone() != "" {
+		return IPAddress{}, fmt.Errorf("IP address %q has a zone", s)
+	}
+	return IPAddress{addr: addr}, nil
+}
+
+// MustParseIPAddress is like ParseIPAddress but panics if s does not parse.
+// It is intended for tests and constants.
+func MustParseIPAddress(s string) IPAddress {
+	ip, err := ParseIPAddress(s)
+	if err != nil {
+		panic(err)
+	}
+	return ip
+}
+
+// IPAddressFrom converts a netip.Addr, dropping any zone.
+func IPAddressFrom(addr netip.Addr) IPAddress {
+	return IPAddress{addr: addr.WithZone("")}
+}
+
+// Addr returns the address as a netip.Addr, or the zero Addr if it is unset.
+func (ip IPAddress) Addr() netip.Addr {
+	return ip.addr
+}
+
+// IsValid reports whether the address is set.
+func (ip IPAddress) IsValid() bool {
+	return ip.addr.IsValid()
+}
+
+// IsZero reports whether the address is unset, so that omitzero omits it.
+func (ip IPAddress) IsZero() bool {
+	return !ip.addr.IsValid()
+}
+
+func (ip IPAddress) Is4() bool {
+	return ip.addr.Is4()
+}
+
+func (ip IPAddress) Is6() bool {
+	return ip.addr.Is6()
+}
+
+// String returns the canonical form of the address, or an empty string if it
+// is unset.
+func (ip IPAddress) String() string {
+	if !ip.addr.IsValid() {
+		return ""
+	}
+	return ip.addr.String()
+}
+
+// Equal reports whether ip and o are the same address.
+func (ip IPAddress) Equal(o IPAddress) bool {
+	return ip == o
+}
+
+func (ip IPAddress) MarshalText() ([]byte, error) {
+	return []byte(ip.String()), nil
+}
+
+func (ip *IPAddress) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*ip = IPAddress{}
+		return nil
+	}
+	parsed, err := ParseIPAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*ip = parsed
+	return nil
+}
+
+func (IPAddress) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Title:       "IPAddress",
+		Description: "An IPv4 or IPv6 address",
+		AnyOf: []*jsonschema.Schema{
+			{Format: "ipv4"},
+			{Format: "ipv6"},
+		},
+		Examples: []interface{}{"10.254.2.10"},
+	}
+}
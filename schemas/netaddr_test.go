@@ -0,0 +1,117 @@
+package schemas
+
+import (
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"testing"
+)
+
+func TestParseMACAddress(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"b0:7b:25:c8:2a:10", "b0:7b:25:c8:2a:10", true},
+		{"B0-7B-25-C8-2A-10", "b0:7b:25:c8:2a:10", true},
+		{"b07b.25c8.2a10", "b0:7b:25:c8:2a:10", true},
+		{"00:00:00:00:00:00", "00:00:00:00:00:00", true},
+		{"00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01", "", false},
+		{"not a mac", "", false},
+	}
+	pattern := regexp.MustCompile(MACAddress{}.JSONSchema().Pattern)
+	for _, tt := range tests {
+		got, err := ParseMACAddress(tt.in)
+		if (err == nil) != tt.ok || got.IsValid() != tt.ok || got.String() != tt.want {
+			t.Errorf("ParseMACAddress(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+		if pattern.MatchString(tt.in) != tt.ok {
+			t.Errorf("schema pattern match of %q = %v, want %v", tt.in, !tt.ok, tt.ok)
+		}
+	}
+}
+
+func TestParseIPAddress(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"10.254.1.12", "10.254.1.12", true},
+		{"FD00:0:0::12", "fd00::12", true},
+		{"fe80::1%eth0", "", false},
+		{"10.254.1", "", false},
+	}
+	for _, tt := range tests {
+		if got, err := ParseIPAddress(tt.in); (err == nil) != tt.ok || got.IsValid() != tt.ok || got.String() != tt.want {
+			t.Errorf("ParseIPAddress(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if ip := IPAddressFrom(netip.MustParseAddr("fe80::1%eth0")); ip.String() != "fe80::1" {
+		t.Errorf("IPAddressFrom() of a zoned address = %s, want the zone dropped", ip)
+	}
+}
+
+func TestAddressesCompareEqual(t *testing.T) {
+	mac := MustParseMACAddress("B0-7B-25-C8-2A-10")
+	if mac != MustParseMACAddress("b07b.25c8.2a10") || mac == MustParseMACAddress("b0:7b:25:c8:2a:11") || mac == (MACAddress{}) {
+		t.Errorf("MACAddress %s does not compare by address", mac)
+	}
+	ip := MustParseIPAddress("FD00::0012")
+	if ip != MustParseIPAddress("fd00::12") || ip == MustParseIPAddress("fd00::13") || ip == (IPAddress{}) {
+		t.Errorf("IPAddress %s does not compare by address", ip)
+	}
+
+	d := InventoryDetail{EthernetInterfaces: []EthernetInterface{{Name: "NIC 1", MAC: MustParseMACAddress("B0:7B:25:C8:2A:10")}}}
+	if e, ok := d.InterfaceByMAC(MustParseMACAddress("b0:7b:25:c8:2a:10")); !ok || e.Name != "NIC 1" {
+		t.Error("InterfaceByMAC() did not match the address")
+	}
+	if _, ok := (InventoryDetail{EthernetInterfaces: []EthernetInterface{{Name: "no MAC"}}}).InterfaceByMAC(MACAddress{}); ok {
+		t.Error("InterfaceByMAC() of an unset address matched an interface without a MAC")
+	}
+}
+
+func TestAddressZeroValues(t *testing.T) {
+	var mac MACAddress
+	var ip IPAddress
+	if mac.IsValid() || mac.String() != "" || mac.HardwareAddr() != nil || !mac.IsZero() {
+		t.Errorf("zero MACAddress = %q, want unset", mac)
+	}
+	if ip.IsValid() || ip.String() != "" || ip.Addr().IsValid() || !ip.IsZero() {
+		t.Errorf("zero IPAddress = %q, want unset", ip)
+	}
+}
+
+func TestAddressJSON(t *testing.T) {
+	in := EthernetInterface{MAC: MustParseMACAddress("B0-7B-25-C8-2A-10"), IP: MustParseIPAddress("FD00::0012")}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"mac":"b0:7b:25:c8:2a:10","ip":"fd00::12"}` {
+		t.Errorf("Marshal() = %s, want canonical addresses", b)
+	}
+	var out EthernetInterface
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.MAC != in.MAC || out.IP != in.IP {
+		t.Errorf("round trip through %s gave MAC %q IP %q", b, out.MAC, out.IP)
+	}
+
+	// Unset addresses are omitted, and empty strings read as unset.
+	if b, err := json.Marshal(EthernetInterface{Name: "eth0"}); err != nil || string(b) != `{"name":"eth0"}` {
+		t.Errorf("Marshal() without addresses = %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"mac": "", "ip": ""}`), &out); err != nil || out.MAC.IsValid() || out.IP.IsValid() {
+		t.Errorf("Unmarshal() of empty addresses = %+v, %v", out, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"ip": "fe80::1%eth0"}`), &out); err == nil {
+		t.Error("Unmarshal() accepted a zoned IPv6 address")
+	}
+	if err := json.Unmarshal([]byte(`{"mac": "not a mac"}`), &out); err == nil {
+		t.Error("Unmarshal() accepted an invalid MAC address")
+	}
+}
@@ -0,0 +1,132 @@
+package schemas
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Optional is a value that may be unset, so that false or 0 can be told apart
+// from absent.  The zero Optional is unset.  Fields should be tagged
+// omitempty,omitzero: omitzero omits an unset value, which marshals as null
+// otherwise, and omitempty keeps the field out of the required list of the
+// generated JSON schema.  null unmarshals as unset.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional set to v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// Get returns the value and whether it is set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// IsSet reports whether the value is set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsZero reports whether the value is unset, for omitzero.
+func (o Optional[T]) IsZero() bool {
+	return !o.set
+}
+
+// OrElse returns the value, or def if it is unset.
+func (o Optional[T]) OrElse(def T) T {
+	if !o.set {
+		return def
+	}
+	return o.value
+}
+
+// String formats the value, or returns the empty string if it is unset.
+func (o Optional[T]) String() string {
+	if !o.set {
+		return ""
+	}
+	return fmt.Sprint(o.value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Optional[T]{}
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Some(v)
+	return nil
+}
+
+// Scan implements sql.Scanner, reading NULL as unset.
+func (o *Optional[T]) Scan(src any) error {
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	*o = Optional[T]{value: n.V, set: n.Valid}
+	return nil
+}
+
+// Value implements driver.Valuer, writing unset as NULL.
+func (o Optional[T]) Value() (driver.Value, error) {
+	return sql.Null[T]{V: o.value, Valid: o.set}.Value()
+}
+
+// JSONSchema describes the value's own schema, or null.
+func (Optional[T]) JSONSchema() *jsonschema.Schema {
+	r := &jsonschema.Reflector{DoNotReference: true, Anonymous: true}
+	var v T
+	s := r.Reflect(&v)
+	s.Version, s.ID = "", ""
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{s, {Type: "null"}},
+	}
+}
+
+// SchemaTypeName names types for jsonschema.Reflector.Namer.  Go names
+// instances of generic types such as Optional[bool] with brackets and
+// package paths, which are not valid in a $ref, so they are named without
+// them instead, e.g. OptionalBool or OptionalComponentFlag.  Other types
+// keep their own name.
+func SchemaTypeName(t reflect.Type) string {
+	base, args, generic := strings.Cut(t.Name(), "[")
+	if !generic {
+		return base
+	}
+	var name strings.Builder
+	name.WriteString(base)
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		first := true
+		for _, r := range arg[strings.LastIndexAny(arg, "./")+1:] {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				continue
+			}
+			if first {
+				r, first = unicode.ToUpper(r), false
+			}
+			name.WriteRune(r)
+		}
+	}
+	return name.String()
+}
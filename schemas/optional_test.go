@@ -0,0 +1,146 @@
+package schemas
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+)
+
+func TestOptionalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   VLAN
+		want string
+	}{
+		{"unset", VLAN{ID: 10}, `{"id":10}`},
+		{"false", VLAN{ID: 10, Enabled: Some(false)}, `{"id":10,"enabled":false}`},
+		{"true", VLAN{ID: 10, Enabled: Some(true)}, `{"id":10,"enabled":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", b, tt.want)
+			}
+			var out VLAN
+			if err := json.Unmarshal(b, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.in {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", b, out, tt.in)
+			}
+		})
+	}
+
+	// Marshaled on its own, rather than as an omitted field, unset is null.
+	if b, err := json.Marshal(Optional[int]{}); err != nil || string(b) != "null" {
+		t.Errorf("Marshal() of unset = %s, %v, want null", b, err)
+	}
+	if b, err := json.Marshal(Some(0)); err != nil || string(b) != "0" {
+		t.Errorf("Marshal() of 0 = %s, %v, want 0", b, err)
+	}
+}
+
+func TestOptionalUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Optional[int]
+		ok   bool
+	}{
+		{`0`, Some(0), true},
+		{`42`, Some(42), true},
+		{`null`, Optional[int]{}, true},
+		{`"42"`, Optional[int]{}, false},
+	}
+	for _, tt := range tests {
+		o := Some(7)
+		err := json.Unmarshal([]byte(tt.in), &o)
+		if (err == nil) != tt.ok || (tt.ok && o != tt.want) {
+			t.Errorf("Unmarshal(%s) = %v (set %v), %v, want %v (set %v)", tt.in, o, o.IsSet(), err, tt.want, tt.want.IsSet())
+		}
+	}
+}
+
+func TestOptionalSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want Optional[bool]
+	}{
+		{"NULL", nil, Optional[bool]{}},
+		{"false", false, Some(false)},
+		{"true", true, Some(true)},
+		{"integer", int64(0), Some(false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Some(true)
+			if err := o.Scan(tt.src); err != nil {
+				t.Fatal(err)
+			}
+			if o != tt.want {
+				t.Errorf("Scan(%v) = %v (set %v), want %v (set %v)", tt.src, o, o.IsSet(), tt.want, tt.want.IsSet())
+			}
+		})
+	}
+	if err := new(Optional[int]).Scan("lots"); err == nil {
+		t.Error("Scan() of a string into an int succeeded")
+	}
+
+	values := []struct {
+		in   Optional[int64]
+		want any
+	}{
+		{Optional[int64]{}, nil},
+		{Some[int64](0), int64(0)},
+		{Some[int64](42), int64(42)},
+	}
+	for _, tt := range values {
+		got, err := tt.in.Value()
+		if err != nil || got != tt.want {
+			t.Errorf("Value() of %v = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestOptionalSchemaNotRequired(t *testing.T) {
+	r := &jsonschema.Reflector{DoNotReference: true}
+	s := r.Reflect(&VLAN{})
+	if !slices.Contains(s.Required, "id") || slices.Contains(s.Required, "enabled") {
+		t.Errorf("required = %v, want id but not enabled", s.Required)
+	}
+	enabled, ok := s.Properties.Get("enabled")
+	if !ok || len(enabled.AnyOf) != 2 || enabled.AnyOf[0].Type != "boolean" || enabled.AnyOf[1].Type != "null" {
+		t.Errorf("enabled schema = %+v, want a boolean or null", enabled)
+	}
+}
+
+func TestSchemaTypeName(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{VLAN{}, "VLAN"},
+		{Optional[bool]{}, "OptionalBool"},
+		{Optional[ByteQuantity]{}, "OptionalByteQuantity"},
+		{Optional[[]string]{}, "OptionalString"},
+	}
+	for _, tt := range tests {
+		if got := SchemaTypeName(reflect.TypeOf(tt.v)); got != tt.want {
+			t.Errorf("SchemaTypeName(%T) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+
+	r := &jsonschema.Reflector{Namer: SchemaTypeName}
+	s := r.Reflect(&VLAN{})
+	if _, ok := s.Definitions["OptionalBool"]; !ok {
+		t.Errorf("definitions = %v, want OptionalBool", slices.Collect(maps.Keys(s.Definitions)))
+	}
+}
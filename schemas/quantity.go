@@ -0,0 +1,154 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// ByteQuantity is an amount of storage or memory in bytes.  It marshals as a
+// human readable string such as "512GiB" or "1.5TB", and unmarshals from such
+// a string or from a plain integer number of bytes.
+type ByteQuantity int64
+
+// Units for ByteQuantity.  Decimal (SI) and binary (IEC) units are distinct:
+// GB is 10^9 bytes and GiB is 2^30 bytes.
+const (
+	Byte ByteQuantity = 1
+
+	KB ByteQuantity = 1000 * Byte
+	MB ByteQuantity = 1000 * KB
+	GB ByteQuantity = 1000 * MB
+	TB ByteQuantity = 1000 * GB
+	PB ByteQuantity = 1000 * TB
+
+	KiB ByteQuantity = 1024 * Byte
+	MiB ByteQuantity = 1024 * KiB
+	GiB ByteQuantity = 1024 * MiB
+	TiB ByteQuantity = 1024 * GiB
+	PiB ByteQuantity = 1024 * TiB
+)
+
+var byteUnits = []struct {
+	name string
+	unit ByteQuantity
+}{
+	{"PiB", PiB}, {"PB", PB},
+	{"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB},
+	{"MiB", MiB}, {"MB", MB},
+	{"KiB", KiB}, {"KB", KB},
+	{"B", Byte},
+}
+
+var byteQuantityRegex = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([A-Za-z]*)$`)
+
+// ParseByteQuantity parses a number with an optional unit, e.g. "512GiB",
+// "1.5 TB" or "4096".  Units are case-insensitive, and a fractional value must
+// come to a whole number of bytes.
+func ParseByteQuantity(s string) (ByteQuantity, error) {
+	m := byteQuantityRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid byte quantity %q", s)
+	}
+	unit := Byte
+	if m[2] != "" {
+		found := false
+		for _, u := range byteUnits {
+			if strings.EqualFold(m[2], u.name) {
+				unit, found = u.unit, true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown unit %q in byte quantity %q", m[2], s)
+		}
+	}
+
+	value, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return 0, fmt.Errorf("invalid byte quantity %q", s)
+	}
+	value.Mul(value, new(big.Rat).SetInt64(int64(unit)))
+	if !value.IsInt() {
+		return 0, fmt.Errorf("byte quantity %q is not a whole number of bytes", s)
+	}
+	if !value.Num().IsInt64() {
+		return 0, fmt.Errorf("byte quantity %q is too large", s)
+	}
+	return ByteQuantity(value.Num().Int64()), nil
+}
+
+// ByteQuantityFromFloat converts a value in the given unit, such as the GiB
+// reported by Redfish, rounding to the nearest byte.
+func ByteQuantityFromFloat(value float64, unit ByteQuantity) ByteQuantity {
+	return ByteQuantity(math.Round(value * float64(unit)))
+}
+
+// In returns the quantity as a whole number of unit, and whether the
+// conversion was exact.
+func (q ByteQuantity) In(unit ByteQuantity) (int64, bool) {
+	return int64(q / unit), q%unit == 0
+}
+
+// Float returns the quantity in unit as a float, e.g. q.Float(GiB).
+func (q ByteQuantity) Float(unit ByteQuantity) float64 {
+	return float64(q) / float64(unit)
+}
+
+// String formats the quantity exactly, in the largest unit that needs no more
+// than three decimal places.
+func (q ByteQuantity) String() string {
+	for _, u := range byteUnits {
+		if q < u.unit && q > -u.unit || u.unit == Byte {
+			continue
+		}
+		thousandths := new(big.Int).Mul(big.NewInt(int64(q)), big.NewInt(1000))
+		whole, rem := new(big.Int).QuoRem(thousandths, big.NewInt(int64(u.unit)), new(big.Int))
+		if rem.Sign() != 0 {
+			continue
+		}
+		s := new(big.Rat).SetFrac(whole, big.NewInt(1000)).FloatString(3)
+		return strings.TrimSuffix(strings.TrimRight(s, "0"), ".") + u.name
+	}
+	return fmt.Sprintf("%dB", int64(q))
+}
+
+func (q ByteQuantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.String())
+}
+
+func (q *ByteQuantity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := ParseByteQuantity(s)
+		if err != nil {
+			return err
+		}
+		*q = parsed
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("byte quantity must be a string or an integer number of bytes: %s", data)
+	}
+	*q = ByteQuantity(n)
+	return nil
+}
+
+func (ByteQuantity) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Title:       "ByteQuantity",
+		Description: "An amount of storage or memory.  Either an integer number of bytes or a number with a decimal (KB, MB, GB, TB, PB) or binary (KiB, MiB, GiB, TiB, PiB) unit.",
+		OneOf: []*jsonschema.Schema{
+			{Type: "integer", Minimum: json.Number("0")},
+			{Type: "string", Pattern: `^[0-9]+(\.[0-9]+)?\s*([kKmMgGtTpP][iI]?[bB]|[bB])?$`},
+		},
+		Examples: []interface{}{"512GiB", "1.5TB", 4096},
+	}
+}
@@ -0,0 +1,103 @@
+package schemas
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseByteQuantity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteQuantity
+		ok   bool
+	}{
+		{"4096", 4096, true},
+		{"512GiB", 512 * GiB, true},
+		{"1.5 TB", 1500 * GB, true},
+		{"1.5tib", 1536 * GiB, true},
+		{"32 kb", 32 * KB, true},
+		{"0.5B", 0, false},
+		{"12 parsecs", 0, false},
+		{"-1GiB", 0, false},
+		{"10000PiB", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteQuantity(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseByteQuantity(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestByteQuantityString(t *testing.T) {
+	tests := []struct {
+		q    ByteQuantity
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1.023KB"},
+		{512 * GiB, "512GiB"},
+		{1500 * GB, "1.5TB"},
+		{480103981056, "468851544KiB"},
+		{999, "999B"},
+		{1536 * MiB, "1.5GiB"},
+		{-2 * KiB, "-2KiB"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.want {
+			t.Errorf("ByteQuantity(%d).String() = %q, want %q", int64(tt.q), got, tt.want)
+		}
+		if tt.q < 0 {
+			continue
+		}
+		if back, err := ParseByteQuantity(tt.q.String()); err != nil || back != tt.q {
+			t.Errorf("ParseByteQuantity(%q) = %d, %v, want %d", tt.q.String(), back, err, int64(tt.q))
+		}
+	}
+}
+
+func TestByteQuantityConversions(t *testing.T) {
+	if got := ByteQuantityFromFloat(0.5, GiB); got != 512*MiB {
+		t.Errorf("ByteQuantityFromFloat(0.5, GiB) = %s", got)
+	}
+	if n, exact := (1536 * MiB).In(GiB); n != 1 || exact {
+		t.Errorf("In(GiB) = %d, %v, want 1 and inexact", n, exact)
+	}
+	if n, exact := (64 * GiB).In(MiB); n != 65536 || !exact {
+		t.Errorf("In(MiB) = %d, %v, want 65536 and exact", n, exact)
+	}
+	if f := (1536 * MiB).Float(GiB); math.Abs(f-1.5) > 1e-9 {
+		t.Errorf("Float(GiB) = %v, want 1.5", f)
+	}
+}
+
+func TestByteQuantityJSON(t *testing.T) {
+	b, err := json.Marshal(MemoryModule{Capacity: 32 * GiB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"capacity":"32GiB"}` {
+		t.Errorf("Marshal() = %s", b)
+	}
+
+	tests := []struct {
+		in   string
+		want ByteQuantity
+		ok   bool
+	}{
+		{`"32GiB"`, 32 * GiB, true},
+		{`34359738368`, 32 * GiB, true},
+		{`"32 GiB"`, 32 * GiB, true},
+		{`1.5`, 0, false},
+		{`"lots"`, 0, false},
+		{`true`, 0, false},
+	}
+	for _, tt := range tests {
+		var q ByteQuantity
+		if err := json.Unmarshal([]byte(tt.in), &q); (err == nil) != tt.ok || q != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", tt.in, q, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package redfish
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+// System is a ComputerSystem together with the resources below it that are
+// needed to derive its components.
+type System struct {
+	ComputerSystem
+	Chassis         *Chassis
+	Processors      []Processor
+	Memory          []Memory
+	NetworkAdapters []NetworkAdapter
+}
+
+// FetchSystem fetches a ComputerSystem, its processors and memory, and the
+// network adapters of its chassis.
+func FetchSystem(f Fetcher, odataID string) (System, error) {
+	var s System
+	if err := get(f, odataID, &s.ComputerSystem); err != nil {
+		return s, err
+	}
+	if s.ComputerSystem.Processors.ODataID != "" {
+		if err := eachMember(f, s.ComputerSystem.Processors.ODataID, func(p Processor) { s.Processors = append(s.Processors, p) }); err != nil {
+			return s, err
+		}
+	}
+	if s.ComputerSystem.Memory.ODataID != "" {
+		if err := eachMember(f, s.ComputerSystem.Memory.ODataID, func(m Memory) { s.Memory = append(s.Memory, m) }); err != nil {
+			return s, err
+		}
+	}
+	if len(s.Links.Chassis) > 0 {
+		var chassis Chassis
+		if err := get(f, s.Links.Chassis[0].ODataID, &chassis); err != nil {
+			return s, err
+		}
+		s.Chassis = &chassis
+		if chassis.NetworkAdapters.ODataID != "" {
+			if err := eachMember(f, chassis.NetworkAdapters.ODataID, func(a NetworkAdapter) { s.NetworkAdapters = append(s.NetworkAdapters, a) }); err != nil {
+				return s, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// nodeID matches the system IDs used by multi-node blades, e.g. Node0, Node1.
+var nodeID = regexp.MustCompile(`^Node(\d+)$`)
+
+// ExpandComponents derives the components below a discovered NodeBMC: one Node
+// per system, and the Processors, NodeAccels (GPUs) and Memory of each node.
+// Nodes are numbered from their system ID when it has the form NodeN, and by
+// position otherwise; processors, accelerators and memory are numbered by
+// position.
+//
+// Arch comes from the node's CPUs and Class from its chassis: Blade chassis
+// are Mountain and anything else River.  Nodes are On or Off according to
+// their PowerState, and Unknown while powering on or off, while processors
+// and memory are Populated, or Empty if Redfish reports them Absent.  Role
+// is left empty, since nothing in Redfish says what a node is for; HSM
+// applies its default role, or an administrator sets one.
+func ExpandComponents(d csm.RedfishDiscovery, systems []System) ([]csm.Component, error) {
+	ep := d.Payload
+	if d.Status != "" && !d.Status.IsSuccess() {
+		return nil, fmt.Errorf("endpoint %s was not discovered successfully: %s", ep.ID, d.Status)
+	}
+	if t := csm.XnameType(ep.ID); t != csm.TypeNodeBMC {
+		return nil, fmt.Errorf("endpoint %s is a %s, not a %s", ep.ID, t, csm.TypeNodeBMC)
+	}
+
+	var components []csm.Component
+	used := map[string]bool{}
+	for i, sys := range systems {
+		n := i
+		if m := nodeID.FindStringSubmatch(sys.ID); m != nil {
+			n, _ = strconv.Atoi(m[1])
+		}
+		node := csm.Component{
+			ID:      fmt.Sprintf("%sn%d", ep.ID, n),
+			Type:    csm.TypeNode,
+			NetType: netType(sys.NetworkAdapters),
+			Arch:    systemArch(sys.Processors),
+			Class:   chassisClass(sys.Chassis),
+			State:   powerState(sys.PowerState),
+			Flag:    csm.FlagOK,
+			Enabled: ep.Enabled,
+		}
+		if used[node.ID] {
+			return nil, fmt.Errorf("endpoint %s has more than one system mapping to %s", ep.ID, node.ID)
+		}
+		used[node.ID] = true
+		components = append(components, node)
+
+		var cpus, accels int
+		for _, p := range sys.Processors {
+			c := child(node, presence(p.Status))
+			if arch := processorArch(p); arch != csm.ArchUnknown {
+				c.Arch = arch
+			}
+			if isAccelerator(p) {
+				c.ID, c.Type = fmt.Sprintf("%sa%d", node.ID, accels), csm.TypeNodeAccel
+				accels++
+			} else {
+				c.ID, c.Type = fmt.Sprintf("%sp%d", node.ID, cpus), csm.TypeProcessor
+				cpus++
+			}
+			components = append(components, c)
+		}
+		for j, m := range sys.Memory {
+			c := child(node, presence(m.Status))
+			c.ID, c.Type = fmt.Sprintf("%sd%d", node.ID, j), csm.TypeMemory
+			components = append(components, c)
+		}
+	}
+	return components, nil
+}
+
+// child returns a component inheriting the node's Arch, Class and Enabled.
+func child(node csm.Component, state csm.ComponentState) csm.Component {
+	return csm.Component{
+		Arch:    node.Arch,
+		Class:   node.Class,
+		State:   state,
+		Flag:    csm.FlagOK,
+		Enabled: node.Enabled,
+	}
+}
+
+// powerState maps a Redfish PowerState onto a node state.  PoweringOn and
+// PoweringOff are Unknown rather than a guess at where the transition ends.
+func powerState(s string) csm.ComponentState {
+	switch s {
+	case "On":
+		return csm.StateOn
+	case "Off":
+		return csm.StateOff
+	}
+	return csm.StateUnknown
+}
+
+func presence(s Status) csm.ComponentState {
+	if s.State == "Absent" {
+		return csm.StateEmpty
+	}
+	return csm.StatePopulated
+}
+
+func isAccelerator(p Processor) bool {
+	switch p.ProcessorType {
+	case "GPU", "Accelerator", "FPGA":
+		return true
+	}
+	return false
+}
+
+func processorArch(p Processor) csm.ComponentArch {
+	a := strings.ToUpper(p.ProcessorArchitecture + " " + p.InstructionSet)
+	switch {
+	case strings.TrimSpace(a) == "":
+		return csm.ArchUnknown
+	case strings.Contains(a, "X86"):
+		return csm.ArchX86
+	case strings.Contains(a, "ARM"):
+		return csm.ArchARM
+	}
+	return csm.ArchOther
+}
+
+// systemArch is the architecture of the first CPU.
+func systemArch(processors []Processor) csm.ComponentArch {
+	for _, p := range processors {
+		if !isAccelerator(p) && p.Status.State != "Absent" {
+			return processorArch(p)
+		}
+	}
+	return csm.ArchUnknown
+}
+
+func chassisClass(c *Chassis) csm.ComponentClass {
+	if c != nil && c.ChassisType == "Blade" {
+		return csm.ClassMountain
+	}
+	return csm.ClassRiver
+}
+
+// netType identifies the high speed network from the node's adapters.
+func netType(adapters []NetworkAdapter) csm.ComponentNetType {
+	if len(adapters) == 0 {
+		return csm.NetNone
+	}
+	for _, a := range adapters {
+		desc := strings.ToLower(a.Manufacturer + " " + a.Model + " " + a.Name + " " + a.Description)
+		switch {
+		case strings.Contains(desc, "slingshot") || strings.Contains(desc, "cassini"):
+			return csm.NetSling
+		case strings.Contains(desc, "infiniband"):
+			return csm.NetInfiniband
+		}
+	}
+	return csm.NetEthernet
+}
@@ -0,0 +1,133 @@
+package redfish
+
+import (
+	"os"
+	"testing"
+
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+func TestExpandComponentsFixture(t *testing.T) {
+	f := FSFetcher{FS: os.DirFS("testdata/hpe")}
+	sys, err := FetchSystem(f, "/redfish/v1/Systems/1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := csm.RedfishDiscovery{Status: csm.DiscoveryOK, Payload: csm.RedfishEndpoint{ID: "x3000c0s2b0", Enabled: schemas.Some(true)}}
+	components, err := ExpandComponents(d, []System{sys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id    string
+		typ   csm.ComponentType
+		state csm.ComponentState
+	}{
+		{"x3000c0s2b0n0", csm.TypeNode, csm.StateOff},
+		{"x3000c0s2b0n0p0", csm.TypeProcessor, csm.StatePopulated},
+		{"x3000c0s2b0n0a0", csm.TypeNodeAccel, csm.StatePopulated},
+		{"x3000c0s2b0n0d0", csm.TypeMemory, csm.StatePopulated},
+		{"x3000c0s2b0n0d1", csm.TypeMemory, csm.StateEmpty},
+	}
+	if len(components) != len(want) {
+		t.Fatalf("got %d components, want %d: %+v", len(components), len(want), components)
+	}
+	for i, w := range want {
+		c := components[i]
+		if c.ID != w.id || c.Type != w.typ || c.State != w.state {
+			t.Errorf("component %d = %s %s %s, want %s %s %s", i, c.ID, c.Type, c.State, w.id, w.typ, w.state)
+		}
+		if c.Class != csm.ClassRiver || c.Flag != csm.FlagOK || c.Enabled != d.Payload.Enabled || c.Role != "" {
+			t.Errorf("component %s = %+v, want a River component with no role inheriting Enabled", c.ID, c)
+		}
+	}
+	if node := components[0]; node.Arch != csm.ArchX86 || node.NetType != csm.NetEthernet {
+		t.Errorf("node Arch %s NetType %s, want X86 and Ethernet", node.Arch, node.NetType)
+	}
+}
+
+func TestExpandComponentsNodes(t *testing.T) {
+	blade := &Chassis{ChassisType: "Blade"}
+	slingshot := []NetworkAdapter{{Manufacturer: "HPE", Model: "Slingshot 11"}}
+	systems := []System{
+		{ComputerSystem: ComputerSystem{ID: "Node1", PowerState: "PoweringOn"}, Chassis: blade, NetworkAdapters: slingshot},
+		{ComputerSystem: ComputerSystem{ID: "Node0", PowerState: "PoweringOff"}, Chassis: blade},
+		{ComputerSystem: ComputerSystem{ID: "Node3", PowerState: "On"}, Processors: []Processor{{ProcessorArchitecture: "ARM"}}},
+	}
+	d := csm.RedfishDiscovery{Payload: csm.RedfishEndpoint{ID: "x1000c0s0b0"}}
+	components, err := ExpandComponents(d, systems)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id      string
+		state   csm.ComponentState
+		class   csm.ComponentClass
+		netType csm.ComponentNetType
+		arch    csm.ComponentArch
+	}{
+		{"x1000c0s0b0n1", csm.StateUnknown, csm.ClassMountain, csm.NetSling, csm.ArchUnknown},
+		{"x1000c0s0b0n0", csm.StateUnknown, csm.ClassMountain, csm.NetNone, csm.ArchUnknown},
+		{"x1000c0s0b0n3", csm.StateOn, csm.ClassRiver, csm.NetNone, csm.ArchARM},
+	}
+	var nodes []csm.Component
+	for _, c := range components {
+		if c.Type == csm.TypeNode {
+			nodes = append(nodes, c)
+		}
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("got %d nodes, want %d", len(nodes), len(want))
+	}
+	for i, w := range want {
+		n := nodes[i]
+		if n.ID != w.id || n.State != w.state || n.Class != w.class || n.NetType != w.netType || n.Arch != w.arch {
+			t.Errorf("node %d = %+v, want %+v", i, n, w)
+		}
+	}
+}
+
+func TestExpandComponentsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       csm.RedfishDiscovery
+		systems []System
+	}{
+		{"failed discovery", csm.RedfishDiscovery{Status: csm.DiscoveryHTTPsGetFailed, Payload: csm.RedfishEndpoint{ID: "x1000c0s0b0"}}, nil},
+		{"not a NodeBMC", csm.RedfishDiscovery{Payload: csm.RedfishEndpoint{ID: "x1000c0r1b0"}}, nil},
+		{
+			"duplicate node",
+			csm.RedfishDiscovery{Payload: csm.RedfishEndpoint{ID: "x1000c0s0b0"}},
+			[]System{{ComputerSystem: ComputerSystem{ID: "Node1"}}, {ComputerSystem: ComputerSystem{ID: "System.Embedded.2"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if components, err := ExpandComponents(tt.d, tt.systems); err == nil {
+				t.Errorf("ExpandComponents() = %+v, want an error", components)
+			}
+		})
+	}
+}
+
+func TestPowerState(t *testing.T) {
+	tests := []struct {
+		in   string
+		want csm.ComponentState
+	}{
+		{"On", csm.StateOn},
+		{"Off", csm.StateOff},
+		{"PoweringOn", csm.StateUnknown},
+		{"PoweringOff", csm.StateUnknown},
+		{"Paused", csm.StateUnknown},
+		{"", csm.StateUnknown},
+	}
+	for _, tt := range tests {
+		if got := powerState(tt.in); got != tt.want {
+			t.Errorf("powerState(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package redfish
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+// Fetcher retrieves a Redfish document by its @odata.id path,
+// e.g. /redfish/v1/Systems/1.
+type Fetcher interface {
+	Fetch(odataID string) ([]byte, error)
+}
+
+// Error is a discovery failure together with the DiscoveryStatus it
+// corresponds to.
+type Error struct {
+	Status csm.DiscoveryStatus
+	Path   string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Status, e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusOf returns the DiscoveryStatus for an error returned by Discover or a
+// Fetcher.  A nil error is DiscoverOK and unclassified errors are treated as
+// HTTPsGetFailed.
+func StatusOf(err error) csm.DiscoveryStatus {
+	if err == nil {
+		return csm.DiscoveryOK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status
+	}
+	return csm.DiscoveryHTTPsGetFailed
+}
+
+// FSFetcher reads captured Redfish documents from a file system laid out like
+// the DMTF mockups, where /redfish/v1/Systems/1 is stored as either
+// redfish/v1/Systems/1/index.json or redfish/v1/Systems/1.json.
+type FSFetcher struct {
+	FS fs.FS
+}
+
+func (f FSFetcher) Fetch(odataID string) ([]byte, error) {
+	name := strings.Trim(path.Clean("/"+odataID), "/")
+	for _, candidate := range []string{path.Join(name, "index.json"), name + ".json"} {
+		data, err := fs.ReadFile(f.FS, candidate)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, &Error{Status: csm.DiscoveryHTTPsGetFailed, Path: odataID, Err: err}
+		}
+	}
+	return nil, &Error{Status: csm.DiscoveryEndpointInvalid, Path: odataID, Err: fs.ErrNotExist}
+}
+
+// MaxDocumentSize bounds the size of a single Redfish document, so that a
+// misbehaving service cannot exhaust memory.
+const MaxDocumentSize = 16 << 20
+
+// CredentialResolver looks up the username and password that a CredentialRef
+// points to, e.g. in a secret store.  An empty username leaves the endpoint's
+// User in place.
+type CredentialResolver func(ref csm.CredentialRef) (user, password string, err error)
+
+// HTTPFetcher retrieves documents from a live Redfish service, or from any
+// http.RoundTripper set on Client that stands in for one.
+type HTTPFetcher struct {
+	Client   *http.Client
+	BaseURL  string // Scheme and host of the service, e.g. https://10.254.2.10
+	User     string
+	Password string
+
+	// CredentialRef, when set and Password is empty, is resolved with
+	// Credentials before the first request.
+	CredentialRef *csm.CredentialRef
+	Credentials   CredentialResolver
+
+	// TLS is the connection state of the most recent request, or nil if it
+	// failed or was not made over TLS.
+	TLS *tls.ConnectionState
+}
+
+// NewHTTPFetcher creates a fetcher for a Redfish endpoint using its FQDN (or
+// IP address) and credentials.  A nil client uses http.DefaultClient.  When
+// the endpoint's certificate is pinned, the fetcher uses a copy of the client
+// whose transport refuses any other certificate during the handshake, so a
+// mismatch fails the first request with VerificationFailed before
+// credentials are sent.  This requires the client's Transport to be nil or
+// an *http.Transport; other RoundTrippers are used as they are.  When the
+// endpoint has a CredentialRef, set Credentials on the returned fetcher to
+// resolve it.
+func NewHTTPFetcher(ep csm.RedfishEndpoint, client *http.Client) *HTTPFetcher {
+	host := ep.FQDN
+	if host == "" {
+		host = ep.IPAddress.String()
+		if ep.IPAddress.Is6() {
+			host = "[" + host + "]"
+		}
+	}
+	f := &HTTPFetcher{
+		Client:   pinnedClient(ep.TLSInfo, client),
+		BaseURL:  "https://" + host,
+		User:     ep.User,
+		Password: ep.Password,
+	}
+	if ep.CredentialRef != nil {
+		ref := *ep.CredentialRef
+		f.CredentialRef = &ref
+	}
+	return f
+}
+
+// NewResolvedHTTPFetcher creates a fetcher for a resolved endpoint whose
+// client applies the resolved TLS policy and request timeout.
+func NewResolvedHTTPFetcher(r csm.ResolvedDiscovery) (*HTTPFetcher, error) {
+	config, err := r.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return NewHTTPFetcher(r.Endpoint, &http.Client{Transport: transport, Timeout: r.Timeout}), nil
+}
+
+// pinnedClient returns a copy of client that only accepts the certificate
+// pinned in info, or client itself if nothing is pinned.
+func pinnedClient(info *csm.TLSInfo, client *http.Client) *http.Client {
+	if info == nil || !info.Pinned {
+		return client
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return client
+	}
+	transport.TLSClientConfig = info.PinnedConfig(transport.TLSClientConfig)
+	pinned := *client
+	pinned.Transport = transport
+	return &pinned
+}
+
+func (f *HTTPFetcher) Fetch(odataID string) ([]byte, error) {
+	if err := f.resolveCredentials(); err != nil {
+		return nil, &Error{Status: csm.DiscoveryVerificationFailed, Path: odataID, Err: err}
+	}
+	u, err := f.requestURL(odataID)
+	if err != nil {
+		return nil, &Error{Status: csm.DiscoveryEndpointInvalid, Path: odataID, Err: err}
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, &Error{Status: csm.DiscoveryEndpointInvalid, Path: odataID, Err: err}
+	}
+	req.Header.Set("Accept", "application/json")
+	if f.User != "" || f.Password != "" {
+		req.SetBasicAuth(f.User, f.Password)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		f.TLS = nil
+		if errors.Is(err, csm.ErrPinMismatch) {
+			return nil, &Error{Status: csm.DiscoveryVerificationFailed, Path: odataID, Err: err}
+		}
+		return nil, &Error{Status: csm.DiscoveryHTTPsGetFailed, Path: odataID, Err: err}
+	}
+	defer resp.Body.Close()
+	f.TLS = resp.TLS
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, &Error{Status: csm.DiscoveryVerificationFailed, Path: odataID, Err: fmt.Errorf("%s", resp.Status)}
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &Error{Status: csm.DiscoveryEndpointInvalid, Path: odataID, Err: fmt.Errorf("%s", resp.Status)}
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, &Error{Status: csm.DiscoveryHTTPsGetFailed, Path: odataID, Err: fmt.Errorf("%s", resp.Status)}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return nil, &Error{Status: csm.DiscoveryHTTPsGetFailed, Path: odataID, Err: err}
+	}
+	if len(data) > MaxDocumentSize {
+		return nil, &Error{Status: csm.DiscoveryEPResponseFailedDecode, Path: odataID, Err: fmt.Errorf("document is larger than %d bytes", MaxDocumentSize)}
+	}
+	return data, nil
+}
+
+// requestURL returns the URL of odataID on the service at BaseURL.  The
+// @odata.id comes from the service itself, so it must be an absolute path
+// without "//" or "@"; otherwise a hostile document could name another host
+// and have the credentials sent there.  Any fragment is dropped.
+func (f *HTTPFetcher) requestURL(odataID string) (*url.URL, error) {
+	odataID, _, _ = strings.Cut(odataID, "#")
+	if !strings.HasPrefix(odataID, "/") || strings.Contains(odataID, "//") || strings.Contains(odataID, "@") {
+		return nil, fmt.Errorf("invalid @odata.id %q", odataID)
+	}
+	u, err := url.Parse(f.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("base URL %q has no host", f.BaseURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + odataID
+	u.RawPath, u.RawQuery, u.Fragment = "", "", ""
+	return u, nil
+}
+
+// resolveCredentials replaces CredentialRef with the credentials it refers
+// to, the first time it is called.
+func (f *HTTPFetcher) resolveCredentials() error {
+	if f.CredentialRef == nil || f.Password != "" {
+		return nil
+	}
+	if f.Credentials == nil {
+		return fmt.Errorf("no resolver for credential reference %s", f.CredentialRef)
+	}
+	user, password, err := f.Credentials(*f.CredentialRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve credential reference %s: %w", f.CredentialRef, err)
+	}
+	if user != "" {
+		f.User = user
+	}
+	f.Password = password
+	f.CredentialRef = nil
+	return nil
+}
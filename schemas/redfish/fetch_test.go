@@ -0,0 +1,206 @@
+package redfish
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+// basicAuthServer serves {} to requests authenticated as root/hunter2, and a
+// document larger than MaxDocumentSize at /large.
+func basicAuthServer(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "root" || password != "hunter2" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/large" {
+			w.Write([]byte(`"` + strings.Repeat("x", MaxDocumentSize) + `"`))
+			return
+		}
+		if r.URL.Path != ServiceRootPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestHTTPFetcherStatus(t *testing.T) {
+	s := basicAuthServer(t)
+	tests := []struct {
+		name     string
+		fetcher  *HTTPFetcher
+		path     string
+		status   csm.DiscoveryStatus
+		resolved bool
+	}{
+		{"ok", &HTTPFetcher{User: "root", Password: "hunter2"}, ServiceRootPath, csm.DiscoveryOK, false},
+		{"wrong password", &HTTPFetcher{User: "root", Password: "wrong"}, ServiceRootPath, csm.DiscoveryVerificationFailed, false},
+		{"not found", &HTTPFetcher{User: "root", Password: "hunter2"}, "/redfish/v1/Nothing", csm.DiscoveryEndpointInvalid, false},
+		{"too large", &HTTPFetcher{User: "root", Password: "hunter2"}, "/large", csm.DiscoveryEPResponseFailedDecode, false},
+		{
+			"credential reference",
+			&HTTPFetcher{CredentialRef: &csm.CredentialRef{Store: "vault", Path: "bmc/x1000c0s0b0"}, Credentials: func(ref csm.CredentialRef) (string, string, error) {
+				if ref.Path != "bmc/x1000c0s0b0" {
+					return "", "", errors.New("no such secret")
+				}
+				return "root", "hunter2", nil
+			}},
+			ServiceRootPath, csm.DiscoveryOK, true,
+		},
+		{
+			"unresolvable credential reference",
+			&HTTPFetcher{User: "root", CredentialRef: &csm.CredentialRef{Path: "bmc/x1000c0s0b0"}},
+			ServiceRootPath, csm.DiscoveryVerificationFailed, false,
+		},
+		{
+			"credential resolver error",
+			&HTTPFetcher{CredentialRef: &csm.CredentialRef{Path: "bmc/missing"}, Credentials: func(csm.CredentialRef) (string, string, error) {
+				return "", "", errors.New("no such secret")
+			}},
+			ServiceRootPath, csm.DiscoveryVerificationFailed, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fetcher.BaseURL = s.URL
+			_, err := tt.fetcher.Fetch(tt.path)
+			if got := StatusOf(err); got != tt.status {
+				t.Errorf("status %s (%v), want %s", got, err, tt.status)
+			}
+			if tt.resolved && (tt.fetcher.CredentialRef != nil || tt.fetcher.User != "root") {
+				t.Errorf("credentials not resolved: %+v", tt.fetcher)
+			}
+		})
+	}
+}
+
+func TestHTTPFetcherStaysOnHost(t *testing.T) {
+	var leaked atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			leaked.Add(1)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+	s := basicAuthServer(t)
+	host := strings.TrimPrefix(other.URL, "http://")
+
+	for _, odataID := range []string{
+		"@" + host + ServiceRootPath,
+		"//" + host + ServiceRootPath,
+		"http://" + host + ServiceRootPath,
+		":" + strings.Split(host, ":")[1] + ServiceRootPath,
+		"/redfish/v1/@" + host,
+	} {
+		t.Run(odataID, func(t *testing.T) {
+			f := &HTTPFetcher{BaseURL: s.URL, User: "root", Password: "hunter2"}
+			_, err := f.Fetch(odataID)
+			if got := StatusOf(err); got != csm.DiscoveryEndpointInvalid {
+				t.Errorf("status %s (%v), want %s", got, err, csm.DiscoveryEndpointInvalid)
+			}
+		})
+	}
+	if got := leaked.Load(); got != 0 {
+		t.Errorf("other host received credentials %d times", got)
+	}
+
+	// A fragment names part of a document, which is fetched whole.
+	f := &HTTPFetcher{BaseURL: s.URL + "/", User: "root", Password: "hunter2"}
+	if _, err := f.Fetch(ServiceRootPath + "#/Links"); err != nil {
+		t.Errorf("Fetch() with a fragment = %v", err)
+	}
+}
+
+func TestNewHTTPFetcher(t *testing.T) {
+	ep := csm.RedfishEndpoint{ID: "x1000c0s0b0", User: "root", CredentialRef: &csm.CredentialRef{Path: "bmc/x1000c0s0b0"}}
+	ep.IPAddress, _ = schemas.ParseIPAddress("fd00::10")
+	f := NewHTTPFetcher(ep, nil)
+	if f.BaseURL != "https://[fd00::10]" {
+		t.Errorf("BaseURL = %s", f.BaseURL)
+	}
+	if f.CredentialRef == nil || f.CredentialRef == ep.CredentialRef || f.CredentialRef.Path != ep.CredentialRef.Path {
+		t.Errorf("CredentialRef = %v, want a copy of the endpoint's", f.CredentialRef)
+	}
+
+	ep.FQDN = "x1000c0s0b0.mgmt"
+	if f := NewHTTPFetcher(ep, nil); f.BaseURL != "https://x1000c0s0b0.mgmt" {
+		t.Errorf("BaseURL = %s, want the FQDN", f.BaseURL)
+	}
+}
+
+func TestNewResolvedHTTPFetcher(t *testing.T) {
+	r := csm.ResolvedDiscovery{
+		Endpoint: csm.RedfishEndpoint{ID: "x1000c0s0b0", FQDN: "x1000c0s0b0.mgmt"},
+		TLS:      csm.TLSPolicy{InsecureSkipVerify: true, MinVersion: "1.2"},
+		Timeout:  7 * time.Second,
+	}
+	f, err := NewResolvedHTTPFetcher(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Client.Timeout != r.Timeout {
+		t.Errorf("client timeout %v, want %v", f.Client.Timeout, r.Timeout)
+	}
+	config := f.Client.Transport.(*http.Transport).TLSClientConfig
+	if !config.InsecureSkipVerify || config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("TLS config = %+v, want the resolved policy", config)
+	}
+
+	r.TLS.MinVersion = "0.9"
+	if _, err := NewResolvedHTTPFetcher(r); err == nil {
+		t.Error("NewResolvedHTTPFetcher() with an invalid TLS policy succeeded")
+	}
+}
+
+func TestPinnedHTTPFetcher(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"RedfishVersion": "1.6.0"}`))
+	}))
+	defer s.Close()
+	fingerprint := csm.CertificateFingerprint(s.Certificate())
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		status      csm.DiscoveryStatus
+		requests    int32
+	}{
+		{"matching pin", fingerprint, csm.DiscoveryOK, 1},
+		{"mismatched pin", strings.Repeat("0", len(fingerprint)), csm.DiscoveryVerificationFailed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			ep := csm.RedfishEndpoint{ID: "x1000c0s0b0", TLSInfo: &csm.TLSInfo{Fingerprint: tt.fingerprint, Pinned: true}}
+			// A plain client does not trust the self-signed certificate; the
+			// pin alone must decide.
+			f := NewHTTPFetcher(ep, &http.Client{})
+			f.BaseURL = s.URL
+			result, err := Discover(f, ep)
+			if got := StatusOf(err); got != tt.status || result.Endpoint.DiscoveryInfo.LastStatus != tt.status {
+				t.Errorf("status %s (%v), want %s", got, err, tt.status)
+			}
+			if tt.status == csm.DiscoveryVerificationFailed && !errors.Is(err, csm.ErrPinMismatch) {
+				t.Errorf("error %v does not wrap ErrPinMismatch", err)
+			}
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("server handled %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,546 @@
+package redfish
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+// ServiceRootPath is the well-known location of the Redfish service root.
+const ServiceRootPath = "/redfish/v1"
+
+// Result is what Discover learned from a Redfish service.
+type Result struct {
+	Endpoint  csm.RedfishEndpoint       // The endpoint with its DiscoveryInfo and UUID filled in
+	Inventory []schemas.InventoryDetail // One entry per ComputerSystem
+}
+
+// Discover walks the service root and its Systems collection, mapping each
+// ComputerSystem onto an InventoryDetail together with its Chassis, the
+// chassis' TrustedComponents, and its Processors, Memory, Drives, PCIeDevices,
+// EthernetInterfaces and NetworkInterfaces.  The endpoint's
+// DiscoveryInfo.LastStatus is set from the outcome.  When some systems fail,
+// the others are still returned along with the error.
+func Discover(f Fetcher, ep csm.RedfishEndpoint) (Result, error) {
+	return discover(f, ep, func(csm.RedfishCollection) bool { return true })
+}
+
+// DiscoverResolved is like Discover, but only walks the collections selected
+// by the endpoint's discovery template.  Use NewResolvedHTTPFetcher for a
+// fetcher that applies the template's TLS policy and timeout.
+func DiscoverResolved(f Fetcher, r csm.ResolvedDiscovery) (Result, error) {
+	return discover(f, r.Endpoint, r.Walks)
+}
+
+// walkFunc reports whether a collection should be walked.
+type walkFunc func(csm.RedfishCollection) bool
+
+func discover(f Fetcher, ep csm.RedfishEndpoint, walks walkFunc) (Result, error) {
+	result := Result{Endpoint: ep}
+	err := discoverRoot(f, &result, walks)
+	if hf, ok := f.(*HTTPFetcher); ok && hf.TLS != nil {
+		if tlsErr := result.Endpoint.ObserveTLS(*hf.TLS, time.Now()); tlsErr != nil {
+			err = errors.Join(&Error{Status: csm.DiscoveryVerificationFailed, Path: ServiceRootPath, Err: tlsErr}, err)
+		}
+	}
+	result.Endpoint.DiscoveryInfo.LastStatus = StatusOf(err)
+	return result, err
+}
+
+func discoverRoot(f Fetcher, result *Result, walks walkFunc) error {
+	var root ServiceRoot
+	if err := get(f, ServiceRootPath, &root); err != nil {
+		return err
+	}
+	result.Endpoint.DiscoveryInfo.RedfishVersion = root.RedfishVersion
+	if result.Endpoint.UID == uuid.Nil {
+		if id, err := uuid.Parse(root.UUID); err == nil {
+			result.Endpoint.UID = id
+		}
+	}
+
+	var errs []error
+	if root.Managers.ODataID != "" && walks(csm.CollectionManagers) {
+		if err := discoverManagers(f, &result.Endpoint, root.Managers.ODataID); err != nil {
+			errs = append(errs, childError(err))
+		}
+	}
+	if root.Systems.ODataID == "" || !walks(csm.CollectionSystems) {
+		return errors.Join(errs...)
+	}
+
+	var systems Collection
+	if err := get(f, root.Systems.ODataID, &systems); err != nil {
+		return errors.Join(append(errs, childError(err))...)
+	}
+	for _, member := range systems.Members {
+		detail, err := discoverSystem(f, member.ODataID, walks)
+		if err != nil {
+			errs = append(errs, childError(err))
+			continue
+		}
+		result.Inventory = append(result.Inventory, detail)
+	}
+	return errors.Join(errs...)
+}
+
+// discoverManagers fills in the endpoint's UUID, MAC address and IP address
+// from its BMC manager when they are not already known.
+func discoverManagers(f Fetcher, ep *csm.RedfishEndpoint, odataID string) error {
+	var managers []Manager
+	if err := eachMember(f, odataID, func(m Manager) { managers = append(managers, m) }); err != nil {
+		return err
+	}
+	for _, m := range managers {
+		if m.ManagerType != "BMC" {
+			continue
+		}
+		if ep.UID == uuid.Nil {
+			if id, err := uuid.Parse(m.UUID); err == nil {
+				ep.UID = id
+			}
+		}
+		if m.EthernetInterfaces.ODataID == "" || (ep.MACAddr.IsValid() && ep.IPAddress.IsValid()) {
+			continue
+		}
+		err := eachMember(f, m.EthernetInterfaces.ODataID, func(e EthernetInterface) {
+			ei := MapEthernetInterface(e)
+			if !ep.MACAddr.IsValid() {
+				ep.MACAddr = ei.MAC
+			}
+			if !ep.IPAddress.IsValid() {
+				ep.IPAddress = ei.IP
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func discoverSystem(f Fetcher, odataID string, walks walkFunc) (schemas.InventoryDetail, error) {
+	var sys ComputerSystem
+	if err := get(f, odataID, &sys); err != nil {
+		return schemas.InventoryDetail{}, err
+	}
+	detail := MapComputerSystem(sys)
+
+	if len(sys.Links.Chassis) > 0 && walks(csm.CollectionChassis) {
+		var chassis Chassis
+		if err := get(f, sys.Links.Chassis[0].ODataID, &chassis); err != nil {
+			return detail, err
+		}
+		MapChassis(chassis, &detail)
+		if err := discoverParents(f, detail.Chassis, chassis.Links.ContainedBy); err != nil {
+			return detail, err
+		}
+		if chassis.TrustedComponents.ODataID != "" {
+			var components []TrustedComponent
+			err := eachMember(f, chassis.TrustedComponents.ODataID, func(c TrustedComponent) {
+				components = append(components, c)
+			})
+			if err != nil {
+				return detail, err
+			}
+			for _, c := range components {
+				tc := MapTrustedComponent(c)
+				if c.Certificates.ODataID != "" {
+					err := eachMember(f, c.Certificates.ODataID, func(cert Certificate) {
+						if cert.CertificateString != "" {
+							tc.Certificates = append(tc.Certificates, cert.CertificateString)
+						}
+					})
+					if err != nil {
+						return detail, err
+					}
+				}
+				detail.TrustedComponents = append(detail.TrustedComponents, tc)
+			}
+		}
+	}
+
+	if sys.EthernetInterfaces.ODataID != "" && walks(csm.CollectionEthernetInterfaces) {
+		err := eachMember(f, sys.EthernetInterfaces.ODataID, func(e EthernetInterface) {
+			detail.EthernetInterfaces = append(detail.EthernetInterfaces, MapEthernetInterface(e))
+		})
+		if err != nil {
+			return detail, err
+		}
+	}
+
+	if sys.Processors.ODataID != "" && walks(csm.CollectionProcessors) {
+		err := eachMember(f, sys.Processors.ODataID, func(p Processor) {
+			if p.Status.State == "Absent" {
+				return
+			}
+			if isAccelerator(p) {
+				detail.Accelerators = append(detail.Accelerators, MapAccelerator(p))
+			} else {
+				detail.Processors = append(detail.Processors, MapProcessor(p))
+			}
+		})
+		if err != nil {
+			return detail, err
+		}
+	}
+
+	if sys.Memory.ODataID != "" && walks(csm.CollectionMemory) {
+		err := eachMember(f, sys.Memory.ODataID, func(m Memory) {
+			if m.Status.State != "Absent" {
+				detail.MemoryModules = append(detail.MemoryModules, MapMemory(m))
+			}
+		})
+		if err != nil {
+			return detail, err
+		}
+	}
+
+	if sys.Storage.ODataID != "" && walks(csm.CollectionStorage) {
+		var errs []error
+		err := eachMember(f, sys.Storage.ODataID, func(st Storage) {
+			for _, link := range st.Drives {
+				var drive Drive
+				if err := get(f, link.ODataID, &drive); err != nil {
+					errs = append(errs, err)
+					return
+				}
+				if drive.Status.State != "Absent" {
+					detail.Drives = append(detail.Drives, MapDrive(drive))
+				}
+			}
+		})
+		if err = errors.Join(append(errs, err)...); err != nil {
+			return detail, err
+		}
+	}
+
+	if walks(csm.CollectionPCIeDevices) {
+		for _, link := range sys.PCIeDevices {
+			var device PCIeDevice
+			if err := get(f, link.ODataID, &device); err != nil {
+				return detail, err
+			}
+			detail.PCIeDevices = append(detail.PCIeDevices, MapPCIeDevice(device))
+		}
+	}
+
+	if sys.NetworkInterfaces.ODataID != "" && walks(csm.CollectionNetworkInterfaces) {
+		var errs []error
+		err := eachMember(f, sys.NetworkInterfaces.ODataID, func(n NetworkInterface) {
+			ni := schemas.NetworkInterface{
+				URI:         n.ODataID,
+				Name:        n.Name,
+				Description: n.Description,
+			}
+			if n.Links.NetworkAdapter.ODataID != "" && walks(csm.CollectionNetworkAdapters) {
+				var adapter NetworkAdapter
+				if err := get(f, n.Links.NetworkAdapter.ODataID, &adapter); err != nil {
+					errs = append(errs, err)
+					return
+				}
+				ni.AdapterID = addAdapter(&detail, MapNetworkAdapter(adapter))
+			}
+			detail.NetworkInterfaces = append(detail.NetworkInterfaces, ni)
+		})
+		if err = errors.Join(append(errs, err)...); err != nil {
+			return detail, err
+		}
+	}
+
+	detail.Summarize()
+	return detail, nil
+}
+
+// maxChassisDepth bounds how far ContainedBy links are followed, in case a
+// service reports a cycle.
+const maxChassisDepth = 8
+
+// discoverParents follows ContainedBy links from a chassis, building its
+// Parent chain.
+func discoverParents(f Fetcher, c *schemas.ChassisDetail, parent Link) error {
+	for depth := 0; parent.ODataID != "" && depth < maxChassisDepth; depth++ {
+		var chassis Chassis
+		if err := get(f, parent.ODataID, &chassis); err != nil {
+			return err
+		}
+		p := MapChassisDetail(chassis)
+		c.Parent = &p
+		c, parent = c.Parent, chassis.Links.ContainedBy
+	}
+	return nil
+}
+
+// MapComputerSystem maps the system-level fields of a ComputerSystem.
+func MapComputerSystem(sys ComputerSystem) schemas.InventoryDetail {
+	detail := schemas.InventoryDetail{
+		URI:            sys.ODataID,
+		UUID:           sys.UUID,
+		Manufacturer:   sys.Manufacturer,
+		SystemType:     sys.SystemType,
+		Name:           sys.Name,
+		Model:          sys.Model,
+		Serial:         sys.SerialNumber,
+		BiosVersion:    sys.BiosVersion,
+		PowerState:     sys.PowerState,
+		ProcessorCount: sys.ProcessorSummary.Count,
+		ProcessorType:  sys.ProcessorSummary.Model,
+		Memory:         schemas.ByteQuantityFromFloat(float64(sys.MemorySummary.TotalSystemMemoryGiB), schemas.GiB),
+	}
+	for _, tm := range sys.TrustedModules {
+		detail.TrustedModules = append(detail.TrustedModules, MapTrustedModule(tm))
+	}
+	return detail
+}
+
+// MapChassis sets the Chassis of detail from a Redfish Chassis.  Use
+// InventoryDetail.ToV1 for the flat chassis fields.
+func MapChassis(chassis Chassis, detail *schemas.InventoryDetail) {
+	c := MapChassisDetail(chassis)
+	detail.Chassis = &c
+}
+
+// MapChassisDetail maps a Redfish Chassis, without its parents.
+func MapChassisDetail(chassis Chassis) schemas.ChassisDetail {
+	return schemas.ChassisDetail{
+		URI:          chassis.ODataID,
+		Type:         chassis.ChassisType,
+		SKU:          chassis.SKU,
+		Serial:       chassis.SerialNumber,
+		AssetTag:     chassis.AssetTag,
+		Manufacturer: chassis.Manufacturer,
+		Model:        chassis.Model,
+	}
+}
+
+// addAdapter adds an adapter to detail unless it is already there, and returns
+// its ID.  The Redfish Id is used unless another adapter already has it, in
+// which case the URI is used instead.
+func addAdapter(detail *schemas.InventoryDetail, a schemas.NetworkAdapter) string {
+	for _, existing := range detail.NetworkAdapters {
+		if existing.URI == a.URI {
+			return existing.ID
+		}
+	}
+	if _, taken := detail.Adapter(a.ID); taken || a.ID == "" {
+		a.ID = a.URI
+	}
+	detail.NetworkAdapters = append(detail.NetworkAdapters, a)
+	return a.ID
+}
+
+// MapEthernetInterface maps a Redfish EthernetInterface.  Every valid address
+// is listed in Addresses, and IP is the first IPv4 address, falling back to
+// the first IPv6 address.  A MAC address that cannot be parsed is left empty.
+func MapEthernetInterface(e EthernetInterface) schemas.EthernetInterface {
+	ei := schemas.EthernetInterface{
+		URI:         e.ODataID,
+		Name:        e.Name,
+		Description: e.Description,
+		Enabled:     interfaceEnabled(e),
+		LinkStatus:  e.LinkStatus,
+		SpeedMbps:   e.SpeedMbps,
+		MTU:         e.MTUSize,
+	}
+	ei.MAC, _ = schemas.ParseMACAddress(e.MACAddress)
+	if e.VLAN != nil && e.VLAN.VLANId != 0 {
+		ei.VLANs = []schemas.VLAN{{ID: e.VLAN.VLANId, Enabled: schemas.Some(e.VLAN.VLANEnable)}}
+	}
+
+	for _, a := range e.IPv4Addresses {
+		ip, err := schemas.ParseIPAddress(a.Address)
+		if err != nil {
+			continue
+		}
+		address := schemas.InterfaceAddress{Address: ip, Origin: a.AddressOrigin}
+		address.Gateway, _ = schemas.ParseIPAddress(a.Gateway)
+		if mask := net.ParseIP(a.SubnetMask).To4(); mask != nil {
+			address.PrefixLength, _ = net.IPMask(mask).Size()
+		}
+		ei.Addresses = append(ei.Addresses, address)
+	}
+	for _, a := range e.IPv6Addresses {
+		if ip, err := schemas.ParseIPAddress(a.Address); err == nil {
+			ei.Addresses = append(ei.Addresses, schemas.InterfaceAddress{Address: ip, PrefixLength: a.PrefixLength, Origin: a.AddressOrigin})
+		}
+	}
+	if len(ei.Addresses) > 0 {
+		ei.IP = ei.Addresses[0].Address
+	}
+	return ei
+}
+
+// interfaceEnabled uses InterfaceEnabled, falling back to Status.State, and
+// is unset if the service reports neither.
+func interfaceEnabled(e EthernetInterface) schemas.Optional[bool] {
+	if e.InterfaceEnabled != nil {
+		return schemas.Some(*e.InterfaceEnabled)
+	}
+	if e.Status.State == "" {
+		return schemas.Optional[bool]{}
+	}
+	return schemas.Some(e.Status.State == "Enabled")
+}
+
+// MapTrustedModule maps a TPM listed in a ComputerSystem.  Redfish does not
+// report PCR banks, endorsement keys or measurements for it.
+func MapTrustedModule(tm TrustedModule) schemas.TrustedModule {
+	return schemas.TrustedModule{
+		InterfaceType: tm.InterfaceType,
+		Firmware:      tm.FirmwareVersion,
+		Status:        tm.Status.State,
+	}
+}
+
+// MapTrustedComponent maps a Redfish TrustedComponent, without the
+// certificates in its Certificates collection.
+func MapTrustedComponent(c TrustedComponent) schemas.TrustedComponent {
+	return schemas.TrustedComponent{
+		URI:          c.ODataID,
+		Type:         c.TrustedComponentType,
+		Manufacturer: c.Manufacturer,
+		Model:        c.Model,
+		Serial:       c.SerialNumber,
+		UUID:         c.UUID,
+		Firmware:     c.FirmwareVersion,
+		Status:       c.Status.State,
+	}
+}
+
+// MapProcessor maps a Redfish Processor that is a CPU.
+func MapProcessor(p Processor) schemas.Processor {
+	return schemas.Processor{
+		URI:            p.ODataID,
+		Socket:         p.Socket,
+		Architecture:   p.ProcessorArchitecture,
+		InstructionSet: p.InstructionSet,
+		Manufacturer:   p.Manufacturer,
+		Model:          p.Model,
+		Serial:         p.SerialNumber,
+		Firmware:       p.FirmwareVersion,
+		TotalCores:     p.TotalCores,
+		TotalThreads:   p.TotalThreads,
+		MaxSpeedMHz:    p.MaxSpeedMHz,
+	}
+}
+
+// MapAccelerator maps a Redfish Processor that is a GPU, FPGA or other
+// accelerator.
+func MapAccelerator(p Processor) schemas.Accelerator {
+	a := schemas.Accelerator{
+		URI:          p.ODataID,
+		Type:         p.ProcessorType,
+		Manufacturer: p.Manufacturer,
+		Model:        p.Model,
+		Serial:       p.SerialNumber,
+		Firmware:     p.FirmwareVersion,
+	}
+	for _, m := range p.ProcessorMemory {
+		a.Memory += schemas.ByteQuantity(m.CapacityMiB) * schemas.MiB
+	}
+	return a
+}
+
+// MapMemory maps a Redfish Memory resource.
+func MapMemory(m Memory) schemas.MemoryModule {
+	return schemas.MemoryModule{
+		URI:          m.ODataID,
+		Name:         m.Name,
+		DeviceType:   m.MemoryDeviceType,
+		Manufacturer: m.Manufacturer,
+		PartNumber:   m.PartNumber,
+		Serial:       m.SerialNumber,
+		Firmware:     m.FirmwareRevision,
+		Capacity:     schemas.ByteQuantity(m.CapacityMiB) * schemas.MiB,
+		SpeedMHz:     m.OperatingSpeedMhz,
+	}
+}
+
+// MapDrive maps a Redfish Drive.
+func MapDrive(d Drive) schemas.Drive {
+	return schemas.Drive{
+		URI:          d.ODataID,
+		Name:         d.Name,
+		MediaType:    d.MediaType,
+		Protocol:     d.Protocol,
+		Manufacturer: d.Manufacturer,
+		Model:        d.Model,
+		Serial:       d.SerialNumber,
+		Firmware:     d.Revision,
+		Capacity:     schemas.ByteQuantity(d.CapacityBytes),
+	}
+}
+
+// MapPCIeDevice maps a Redfish PCIeDevice.
+func MapPCIeDevice(d PCIeDevice) schemas.PCIeDevice {
+	return schemas.PCIeDevice{
+		URI:          d.ODataID,
+		Name:         d.Name,
+		DeviceType:   d.DeviceType,
+		Manufacturer: d.Manufacturer,
+		Model:        d.Model,
+		PartNumber:   d.PartNumber,
+		Serial:       d.SerialNumber,
+		Firmware:     d.FirmwareVersion,
+	}
+}
+
+// MapNetworkAdapter maps a Redfish NetworkAdapter.
+func MapNetworkAdapter(a NetworkAdapter) schemas.NetworkAdapter {
+	return schemas.NetworkAdapter{
+		ID:           a.ID,
+		URI:          a.ODataID,
+		Manufacturer: a.Manufacturer,
+		Name:         a.Name,
+		Model:        a.Model,
+		Serial:       a.SerialNumber,
+		Description:  a.Description,
+	}
+}
+
+// get fetches a document and decodes it into v.
+func get(f Fetcher, odataID string, v interface{}) error {
+	data, err := f.Fetch(odataID)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &Error{Status: csm.DiscoveryEPResponseFailedDecode, Path: odataID, Err: err}
+	}
+	return nil
+}
+
+// eachMember fetches every member of a collection and passes it to fn.
+func eachMember[T any](f Fetcher, odataID string, fn func(T)) error {
+	var c Collection
+	if err := get(f, odataID, &c); err != nil {
+		return err
+	}
+	for _, m := range c.Members {
+		var member T
+		if err := get(f, m.ODataID, &member); err != nil {
+			return err
+		}
+		fn(member)
+	}
+	return nil
+}
+
+// childError reports a failure below the service root as
+// ChildVerificationFailed, except for responses that could not be decoded.
+func childError(err error) error {
+	if err == nil || StatusOf(err) == csm.DiscoveryEPResponseFailedDecode {
+		return err
+	}
+	var e *Error
+	path := ""
+	if errors.As(err, &e) {
+		path = e.Path
+	}
+	return &Error{Status: csm.DiscoveryChildVerificationFailed, Path: path, Err: err}
+}
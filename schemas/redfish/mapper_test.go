@@ -0,0 +1,295 @@
+package redfish
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+func TestDiscoverVendorFixtures(t *testing.T) {
+	tests := []struct {
+		vendor         string
+		redfishVersion string
+		endpointUUID   string
+		bmcMAC         string
+		bmcIP          string
+		uuid           string
+		manufacturer   string
+		powerState     string
+		processors     int
+		accelerators   int
+		memoryModules  int
+		memory         schemas.ByteQuantity
+		drives         int
+		pcieDevices    int
+		mac            string
+		ip             string
+		adapters       int
+		trusted        int
+	}{
+		{
+			vendor:         "dell",
+			redfishVersion: "1.11.0",
+			endpointUUID:   "3256444f-c0b7-4a80-8050-00d04f434c4c",
+			bmcMAC:         "b0:7b:25:c8:2a:1e",
+			bmcIP:          "10.254.1.12",
+			uuid:           "4c4c4544-0042-3610-8052-b3c04f333333",
+			manufacturer:   "Dell Inc.",
+			powerState:     "On",
+			processors:     2,
+			memoryModules:  2,
+			memory:         64 * schemas.GiB,
+			drives:         1,
+			pcieDevices:    1,
+			mac:            "b0:7b:25:c8:2a:10",
+			ip:             "10.1.0.12",
+		},
+		{
+			vendor:         "hpe",
+			redfishVersion: "1.13.0",
+			endpointUUID:   "d4fcf66d-4a1c-5b5e-a1a8-4e3e67e4b1a2",
+			bmcMAC:         "94:40:c9:3e:12:98",
+			bmcIP:          "10.254.1.13",
+			uuid:           "38393350-3830-5a43-3233-313230334a4b",
+			manufacturer:   "HPE",
+			powerState:     "Off",
+			processors:     1,
+			accelerators:   1,
+			memoryModules:  1,
+			memory:         32 * schemas.GiB,
+			mac:            "94:40:c9:3e:12:9a",
+			adapters:       1,
+			trusted:        1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vendor, func(t *testing.T) {
+			result, err := Discover(FSFetcher{FS: os.DirFS("testdata/" + tt.vendor)}, csm.RedfishEndpoint{ID: "x1000c0s0b0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ep := result.Endpoint
+			if ep.DiscoveryInfo.LastStatus != csm.DiscoveryOK || ep.DiscoveryInfo.RedfishVersion != tt.redfishVersion || ep.UID.String() != tt.endpointUUID {
+				t.Errorf("endpoint = %+v", ep)
+			}
+			if ep.MACAddr.String() != tt.bmcMAC || ep.IPAddress.String() != tt.bmcIP {
+				t.Errorf("endpoint MAC %s IP %s, want %s and %s from the manager", ep.MACAddr, ep.IPAddress, tt.bmcMAC, tt.bmcIP)
+			}
+			if len(result.Inventory) != 1 {
+				t.Fatalf("got %d systems, want 1", len(result.Inventory))
+			}
+
+			d := result.Inventory[0]
+			if d.UUID != tt.uuid || d.Manufacturer != tt.manufacturer || d.PowerState != tt.powerState {
+				t.Errorf("system UUID %s manufacturer %s power %s", d.UUID, d.Manufacturer, d.PowerState)
+			}
+			counts := []struct {
+				name      string
+				got, want int
+			}{
+				{"processors", len(d.Processors), tt.processors},
+				{"accelerators", len(d.Accelerators), tt.accelerators},
+				{"memory modules", len(d.MemoryModules), tt.memoryModules},
+				{"drives", len(d.Drives), tt.drives},
+				{"PCIe devices", len(d.PCIeDevices), tt.pcieDevices},
+				{"network adapters", len(d.NetworkAdapters), tt.adapters},
+				{"network interfaces", len(d.NetworkInterfaces), tt.adapters},
+				{"trusted components", len(d.TrustedComponents), tt.trusted},
+				{"trusted modules", len(d.TrustedModules), 1},
+				{"ethernet interfaces", len(d.EthernetInterfaces), 1},
+			}
+			for _, c := range counts {
+				if c.got != c.want {
+					t.Errorf("%d %s, want %d", c.got, c.name, c.want)
+				}
+			}
+			if d.Memory != tt.memory || d.TotalMemory() != tt.memory {
+				t.Errorf("memory %s, modules total %s, want %s", d.Memory, d.TotalMemory(), tt.memory)
+			}
+			if d.Chassis == nil || d.Chassis.Type != "RackMount" || d.Chassis.Manufacturer != tt.manufacturer {
+				t.Errorf("chassis = %+v", d.Chassis)
+			}
+			if e := d.EthernetInterfaces[0]; e.MAC.String() != tt.mac || e.IP.String() != tt.ip {
+				t.Errorf("ethernet interface MAC %s IP %s, want %s and %s", e.MAC, e.IP, tt.mac, tt.ip)
+			}
+			for _, ni := range d.NetworkInterfaces {
+				if _, ok := d.Adapter(ni.AdapterID); !ok {
+					t.Errorf("network interface %s refers to missing adapter %q", ni.URI, ni.AdapterID)
+				}
+			}
+			for _, tc := range d.TrustedComponents {
+				if len(tc.Certificates) != 1 {
+					t.Errorf("trusted component %s has %d certificates, want 1", tc.URI, len(tc.Certificates))
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoverFailures(t *testing.T) {
+	dell, err := fs.Sub(os.DirFS("testdata"), "dell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := fs.ReadFile(dell, "redfish/v1/index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		fs     fs.FS
+		status csm.DiscoveryStatus
+	}{
+		{"no service root", fstest.MapFS{}, csm.DiscoveryEndpointInvalid},
+		{"undecodable service root", fstest.MapFS{"redfish/v1.json": {Data: []byte(`{"RedfishVersion": `)}}, csm.DiscoveryEPResponseFailedDecode},
+		{"missing systems", fstest.MapFS{"redfish/v1.json": {Data: root}}, csm.DiscoveryChildVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Discover(FSFetcher{FS: tt.fs}, csm.RedfishEndpoint{ID: "x1000c0s0b0"})
+			if err == nil {
+				t.Fatal("Discover() succeeded")
+			}
+			if got := StatusOf(err); got != tt.status || result.Endpoint.DiscoveryInfo.LastStatus != tt.status {
+				t.Errorf("status %s, LastStatus %s, want %s", got, result.Endpoint.DiscoveryInfo.LastStatus, tt.status)
+			}
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Errorf("error %v is not an *Error", err)
+			}
+		})
+	}
+}
+
+func TestDiscoverResolvedSkipsCollections(t *testing.T) {
+	r := csm.ResolvedDiscovery{
+		Endpoint:    csm.RedfishEndpoint{ID: "x1000c0s0b0"},
+		Collections: []csm.RedfishCollection{csm.CollectionSystems, csm.CollectionProcessors},
+	}
+	result, err := DiscoverResolved(FSFetcher{FS: os.DirFS("testdata/dell")}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := result.Inventory[0]
+	if len(d.Processors) != 2 || len(d.MemoryModules) != 0 || d.Chassis != nil || len(d.EthernetInterfaces) != 0 {
+		t.Errorf("walked collections that were not selected: %+v", d)
+	}
+	if result.Endpoint.MACAddr.IsValid() {
+		t.Errorf("walked Managers although it was not selected: %+v", result.Endpoint)
+	}
+}
+
+func TestMapEthernetInterface(t *testing.T) {
+	enabled := false
+	tests := []struct {
+		name    string
+		in      EthernetInterface
+		mac     string
+		ip      string
+		prefix  int
+		enabled schemas.Optional[bool]
+	}{
+		{
+			name: "IPv4 preferred",
+			in: EthernetInterface{
+				MACAddress:    "B0-7B-25-C8-2A-10",
+				IPv6Addresses: []IPv6Address{{Address: "fd00::12", PrefixLength: 64}},
+				IPv4Addresses: []IPv4Address{{Address: "10.1.0.12", SubnetMask: "255.255.240.0"}},
+				Status:        Status{State: "Enabled"},
+			},
+			mac: "b0:7b:25:c8:2a:10", ip: "10.1.0.12", prefix: 20, enabled: schemas.Some(true),
+		},
+		{
+			name: "IPv6 fallback",
+			in: EthernetInterface{
+				IPv4Addresses: []IPv4Address{{Address: "0.0.0.0.0"}},
+				IPv6Addresses: []IPv6Address{{Address: "fd00::12", PrefixLength: 64}},
+			},
+			ip: "fd00::12", prefix: 64,
+		},
+		{
+			name:    "invalid MAC and InterfaceEnabled over Status",
+			in:      EthernetInterface{MACAddress: "not a mac", InterfaceEnabled: &enabled, Status: Status{State: "Enabled"}},
+			enabled: schemas.Some(false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MapEthernetInterface(tt.in)
+			if got.MAC.String() != tt.mac || got.IP.String() != tt.ip || got.Enabled != tt.enabled {
+				t.Errorf("MAC %q IP %q Enabled %v, want %q %q %v", got.MAC, got.IP, got.Enabled, tt.mac, tt.ip, tt.enabled)
+			}
+			if tt.ip != "" && got.Addresses[0].PrefixLength != tt.prefix {
+				t.Errorf("prefix length %d, want %d", got.Addresses[0].PrefixLength, tt.prefix)
+			}
+		})
+	}
+}
+
+func TestMapEthernetInterfaceLink(t *testing.T) {
+	got := MapEthernetInterface(EthernetInterface{
+		LinkStatus: "LinkUp",
+		SpeedMbps:  25000,
+		MTUSize:    9000,
+		VLAN:       &VLAN{VLANEnable: true, VLANId: 2},
+		IPv4Addresses: []IPv4Address{
+			{Address: "10.1.0.12", SubnetMask: "255.255.0.0", AddressOrigin: "DHCP", Gateway: "10.1.0.1"},
+		},
+		IPv6Addresses: []IPv6Address{{Address: "fe80::1%eth0", PrefixLength: 64}},
+	})
+	if got.LinkStatus != schemas.LinkUp || got.SpeedMbps != 25000 || got.MTU != 9000 || !got.IsUp() {
+		t.Errorf("link %s speed %d MTU %d", got.LinkStatus, got.SpeedMbps, got.MTU)
+	}
+	if len(got.VLANs) != 1 || got.VLANs[0].ID != 2 || !got.VLANs[0].Enabled.OrElse(false) {
+		t.Errorf("VLANs = %+v", got.VLANs)
+	}
+	want := []schemas.InterfaceAddress{{Address: schemas.MustParseIPAddress("10.1.0.12"), PrefixLength: 16, Origin: "DHCP", Gateway: schemas.MustParseIPAddress("10.1.0.1")}}
+	if !reflect.DeepEqual(got.Addresses, want) {
+		t.Errorf("Addresses = %+v, want %+v without the zoned link-local address", got.Addresses, want)
+	}
+
+	if got := MapEthernetInterface(EthernetInterface{VLAN: &VLAN{}}); got.VLANs != nil {
+		t.Errorf("VLANs of an interface without a VLAN ID = %+v", got.VLANs)
+	}
+}
+
+func TestMapCapacities(t *testing.T) {
+	tests := []struct {
+		name string
+		got  schemas.ByteQuantity
+		want schemas.ByteQuantity
+	}{
+		{"memory", MapMemory(Memory{CapacityMiB: 32768}).Capacity, 32 * schemas.GiB},
+		{"drive", MapDrive(Drive{CapacityBytes: 480103981056}).Capacity, 480103981056},
+		{"accelerator", MapAccelerator(Processor{ProcessorMemory: []ProcessorMemory{{CapacityMiB: 8192}, {CapacityMiB: 8192}}}).Memory, 16 * schemas.GiB},
+		{"system", MapComputerSystem(ComputerSystem{MemorySummary: MemorySummary{TotalSystemMemoryGiB: 0.5}}).Memory, 512 * schemas.MiB},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s capacity %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestIsAccelerator(t *testing.T) {
+	tests := []struct {
+		processor Processor
+		want      bool
+	}{
+		{Processor{ProcessorType: "CPU"}, false},
+		{Processor{ProcessorType: "GPU"}, true},
+		{Processor{ProcessorType: "FPGA"}, true},
+	}
+	for _, tt := range tests {
+		if got := isAccelerator(tt.processor); got != tt.want {
+			t.Errorf("isAccelerator(%s) = %v, want %v", tt.processor.ProcessorType, got, tt.want)
+		}
+	}
+}
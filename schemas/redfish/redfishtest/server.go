@@ -0,0 +1,489 @@
+// Package redfishtest provides an httptest-based mock Redfish service for
+// testing discovery consumers without live BMCs.
+package redfishtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+	"github.com/openchami/schemas/schemas/redfish"
+)
+
+// DefaultRedfishVersion is reported by the service root when the endpoint
+// fixture does not set DiscoveryInfo.RedfishVersion.
+const DefaultRedfishVersion = "1.6.0"
+
+const managerPath = "/redfish/v1/Managers/BMC"
+
+// Server serves a ServiceRoot/Systems/Chassis/Managers tree generated from
+// InventoryDetail and RedfishEndpoint fixtures over TLS.  When the endpoint
+// has a User or Password, every request must present them with basic auth.
+type Server struct {
+	*httptest.Server
+	Endpoint csm.RedfishEndpoint
+
+	mu      sync.Mutex
+	docs    map[string]interface{}
+	failure csm.DiscoveryStatus
+}
+
+// NewServer starts a server for the endpoint with one ComputerSystem and
+// Chassis per InventoryDetail.  The returned Endpoint has its URI set to the
+// server's service root.  Callers must Close the server.
+func NewServer(ep csm.RedfishEndpoint, inventory []schemas.InventoryDetail) *Server {
+	s := &Server{Endpoint: ep, docs: buildDocuments(ep, inventory)}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serve))
+	s.StartTLS()
+	s.Endpoint.URI = s.URL + redfish.ServiceRootPath
+	return s
+}
+
+// Fetcher returns a fetcher that trusts the server's certificate and uses the
+// endpoint's credentials.  If the endpoint's TLSInfo is pinned, the fetcher
+// only accepts the pinned certificate, as NewHTTPFetcher's do.
+func (s *Server) Fetcher() *redfish.HTTPFetcher {
+	f := redfish.NewHTTPFetcher(s.Endpoint, s.Client())
+	f.BaseURL = s.URL
+	return f
+}
+
+// InjectFailure makes the server misbehave like an endpoint whose
+// DiscoveryInfo.LastStatus is status:
+//
+//   - EndpointInvalid: the service root is not found
+//   - EPResponseFailedDecode: the service root is not valid JSON
+//   - HTTPsGetFailed: connections are closed without a response
+//   - NotYetQueried: every request gets 503, like a BMC that is still starting.
+//     Discovery against it reports HTTPsGetFailed.
+//   - VerificationFailed: every request is rejected as unauthorized
+//   - ChildVerificationFailed: the service root is served, but systems are not found
+//
+// DiscoverOK or an empty status restores normal behavior.
+func (s *Server) InjectFailure(status csm.DiscoveryStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = status
+}
+
+// SetDocument replaces or adds the document served at odataID.
+func (s *Server) SetDocument(odataID string, doc interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[odataID] = doc
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	failure := s.failure
+	doc, found := s.docs[r.URL.Path]
+	s.mu.Unlock()
+
+	isRoot := r.URL.Path == redfish.ServiceRootPath || r.URL.Path == redfish.ServiceRootPath+"/"
+	if isRoot {
+		doc, found = s.docs[redfish.ServiceRootPath]
+	}
+
+	switch failure {
+	case csm.DiscoveryHTTPsGetFailed:
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		http.Error(w, "connection failed", http.StatusBadGateway)
+		return
+	case csm.DiscoveryNotYetQueried:
+		http.Error(w, "service is starting", http.StatusServiceUnavailable)
+		return
+	case csm.DiscoveryVerificationFailed:
+		unauthorized(w)
+		return
+	}
+
+	if s.Endpoint.User != "" || s.Endpoint.Password != "" {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != s.Endpoint.User || password != s.Endpoint.Password {
+			unauthorized(w)
+			return
+		}
+	}
+
+	switch {
+	case failure == csm.DiscoveryEndpointInvalid && isRoot:
+		found = false
+	case failure == csm.DiscoveryEPResponseFailedDecode && isRoot:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"RedfishVersion": `)
+		return
+	case failure == csm.DiscoveryChildVerificationFailed && r.URL.Path != redfish.ServiceRootPath+"/Systems" && !isRoot:
+		found = false
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// buildDocuments generates the Redfish tree for the fixtures, keyed by @odata.id.
+func buildDocuments(ep csm.RedfishEndpoint, inventory []schemas.InventoryDetail) map[string]interface{} {
+	docs := make(map[string]interface{})
+
+	version := ep.DiscoveryInfo.RedfishVersion
+	if version == "" {
+		version = DefaultRedfishVersion
+	}
+	docs[redfish.ServiceRootPath] = redfish.ServiceRoot{
+		ODataID:        redfish.ServiceRootPath,
+		ID:             "RootService",
+		Name:           "Root Service",
+		RedfishVersion: version,
+		UUID:           ep.UID.String(),
+		Systems:        redfish.Link{ODataID: "/redfish/v1/Systems"},
+		Chassis:        redfish.Link{ODataID: "/redfish/v1/Chassis"},
+		Managers:       redfish.Link{ODataID: "/redfish/v1/Managers"},
+	}
+
+	var systems, chassis []redfish.Link
+	for i, detail := range inventory {
+		detail = detail.ToV2()
+		systemPath := fmt.Sprintf("/redfish/v1/Systems/%d", i+1)
+		chassisPath := fmt.Sprintf("/redfish/v1/Chassis/%d", i+1)
+		systems = append(systems, redfish.Link{ODataID: systemPath})
+		chassis = append(chassis, redfish.Link{ODataID: chassisPath})
+
+		sys := redfish.ComputerSystem{
+			ODataID:          systemPath,
+			ID:               fmt.Sprint(i + 1),
+			Name:             detail.Name,
+			UUID:             detail.UUID,
+			Manufacturer:     detail.Manufacturer,
+			SystemType:       detail.SystemType,
+			Model:            detail.Model,
+			SerialNumber:     detail.Serial,
+			BiosVersion:      detail.BiosVersion,
+			PowerState:       detail.PowerState,
+			ProcessorSummary: redfish.ProcessorSummary{Count: detail.ProcessorCount, Model: detail.ProcessorType},
+			MemorySummary:    redfish.MemorySummary{TotalSystemMemoryGiB: float32(detail.Memory.Float(schemas.GiB))},
+			Links: redfish.SystemLinks{
+				Chassis:   []redfish.Link{{ODataID: chassisPath}},
+				ManagedBy: []redfish.Link{{ODataID: managerPath}},
+			},
+			Status: redfish.Status{State: "Enabled", Health: "OK"},
+		}
+		for _, tm := range detail.TrustedModules {
+			sys.TrustedModules = append(sys.TrustedModules, redfish.TrustedModule{
+				InterfaceType:   tm.InterfaceType,
+				FirmwareVersion: tm.Firmware,
+				Status:          redfish.Status{State: tm.Status},
+			})
+		}
+
+		var ethernet []redfish.Link
+		for j, e := range detail.EthernetInterfaces {
+			path := fmt.Sprintf("%s/EthernetInterfaces/%d", systemPath, j+1)
+			ethernet = append(ethernet, redfish.Link{ODataID: path})
+			docs[path] = ethernetInterface(path, fmt.Sprint(j+1), e)
+		}
+		sys.EthernetInterfaces = redfish.Link{ODataID: systemPath + "/EthernetInterfaces"}
+		docs[sys.EthernetInterfaces.ODataID] = collection(sys.EthernetInterfaces.ODataID, "Ethernet Interface Collection", ethernet)
+
+		var interfaces, adapters []redfish.Link
+		adapterPaths := map[string]string{}
+		for j, a := range detail.NetworkAdapters {
+			path := fmt.Sprintf("%s/NetworkAdapters/%d", chassisPath, j+1)
+			adapters = append(adapters, redfish.Link{ODataID: path})
+			adapterPaths[a.ID] = path
+			docs[path] = redfish.NetworkAdapter{
+				ODataID:      path,
+				ID:           a.ID,
+				Name:         a.Name,
+				Description:  a.Description,
+				Manufacturer: a.Manufacturer,
+				Model:        a.Model,
+				SerialNumber: a.Serial,
+			}
+		}
+		for j, n := range detail.NetworkInterfaces {
+			path := fmt.Sprintf("%s/NetworkInterfaces/%d", systemPath, j+1)
+			interfaces = append(interfaces, redfish.Link{ODataID: path})
+			docs[path] = redfish.NetworkInterface{
+				ODataID:     path,
+				ID:          fmt.Sprint(j + 1),
+				Name:        n.Name,
+				Description: n.Description,
+				Links:       redfish.NetworkInterfaceLinks{NetworkAdapter: redfish.Link{ODataID: adapterPaths[n.AdapterID]}},
+			}
+		}
+		sys.NetworkInterfaces = redfish.Link{ODataID: systemPath + "/NetworkInterfaces"}
+		docs[sys.NetworkInterfaces.ODataID] = collection(sys.NetworkInterfaces.ODataID, "Network Interface Collection", interfaces)
+
+		var processors []redfish.Link
+		for _, p := range detail.Processors {
+			path := fmt.Sprintf("%s/Processors/%d", systemPath, len(processors)+1)
+			processors = append(processors, redfish.Link{ODataID: path})
+			docs[path] = redfish.Processor{
+				ODataID:               path,
+				ID:                    fmt.Sprint(len(processors)),
+				Socket:                p.Socket,
+				ProcessorType:         "CPU",
+				ProcessorArchitecture: p.Architecture,
+				InstructionSet:        p.InstructionSet,
+				Manufacturer:          p.Manufacturer,
+				Model:                 p.Model,
+				SerialNumber:          p.Serial,
+				FirmwareVersion:       p.Firmware,
+				TotalCores:            p.TotalCores,
+				TotalThreads:          p.TotalThreads,
+				MaxSpeedMHz:           p.MaxSpeedMHz,
+				Status:                redfish.Status{State: "Enabled", Health: "OK"},
+			}
+		}
+		for _, a := range detail.Accelerators {
+			path := fmt.Sprintf("%s/Processors/%d", systemPath, len(processors)+1)
+			processors = append(processors, redfish.Link{ODataID: path})
+			accel := redfish.Processor{
+				ODataID:         path,
+				ID:              fmt.Sprint(len(processors)),
+				ProcessorType:   a.Type,
+				Manufacturer:    a.Manufacturer,
+				Model:           a.Model,
+				SerialNumber:    a.Serial,
+				FirmwareVersion: a.Firmware,
+				Status:          redfish.Status{State: "Enabled", Health: "OK"},
+			}
+			if a.Memory != 0 {
+				accel.ProcessorMemory = []redfish.ProcessorMemory{{CapacityMiB: int(a.Memory / schemas.MiB)}}
+			}
+			docs[path] = accel
+		}
+		sys.Processors = redfish.Link{ODataID: systemPath + "/Processors"}
+		docs[sys.Processors.ODataID] = collection(sys.Processors.ODataID, "Processor Collection", processors)
+
+		var memory []redfish.Link
+		for j, m := range detail.MemoryModules {
+			path := fmt.Sprintf("%s/Memory/%d", systemPath, j+1)
+			memory = append(memory, redfish.Link{ODataID: path})
+			docs[path] = redfish.Memory{
+				ODataID:           path,
+				ID:                fmt.Sprint(j + 1),
+				Name:              m.Name,
+				MemoryDeviceType:  m.DeviceType,
+				CapacityMiB:       int(m.Capacity / schemas.MiB),
+				OperatingSpeedMhz: m.SpeedMHz,
+				Manufacturer:      m.Manufacturer,
+				PartNumber:        m.PartNumber,
+				SerialNumber:      m.Serial,
+				FirmwareRevision:  m.Firmware,
+				Status:            redfish.Status{State: "Enabled", Health: "OK"},
+			}
+		}
+		sys.Memory = redfish.Link{ODataID: systemPath + "/Memory"}
+		docs[sys.Memory.ODataID] = collection(sys.Memory.ODataID, "Memory Collection", memory)
+
+		storagePath := systemPath + "/Storage/1"
+		storage := redfish.Storage{ODataID: storagePath, ID: "1", Name: "Storage"}
+		for j, d := range detail.Drives {
+			path := fmt.Sprintf("%s/Drives/%d", storagePath, j+1)
+			storage.Drives = append(storage.Drives, redfish.Link{ODataID: path})
+			docs[path] = redfish.Drive{
+				ODataID:       path,
+				ID:            fmt.Sprint(j + 1),
+				Name:          d.Name,
+				MediaType:     d.MediaType,
+				Protocol:      d.Protocol,
+				Manufacturer:  d.Manufacturer,
+				Model:         d.Model,
+				SerialNumber:  d.Serial,
+				Revision:      d.Firmware,
+				CapacityBytes: int64(d.Capacity),
+				Status:        redfish.Status{State: "Enabled", Health: "OK"},
+			}
+		}
+		docs[storagePath] = storage
+		sys.Storage = redfish.Link{ODataID: systemPath + "/Storage"}
+		docs[sys.Storage.ODataID] = collection(sys.Storage.ODataID, "Storage Collection", []redfish.Link{{ODataID: storagePath}})
+
+		for j, d := range detail.PCIeDevices {
+			path := fmt.Sprintf("%s/PCIeDevices/%d", chassisPath, j+1)
+			sys.PCIeDevices = append(sys.PCIeDevices, redfish.Link{ODataID: path})
+			docs[path] = redfish.PCIeDevice{
+				ODataID:         path,
+				ID:              fmt.Sprint(j + 1),
+				Name:            d.Name,
+				DeviceType:      d.DeviceType,
+				Manufacturer:    d.Manufacturer,
+				Model:           d.Model,
+				PartNumber:      d.PartNumber,
+				SerialNumber:    d.Serial,
+				FirmwareVersion: d.Firmware,
+				Status:          redfish.Status{State: "Enabled", Health: "OK"},
+			}
+		}
+		docs[systemPath] = sys
+
+		var cd schemas.ChassisDetail
+		if detail.Chassis != nil {
+			cd = *detail.Chassis
+		}
+		ch := chassisDocument(chassisPath, fmt.Sprint(i+1), cd)
+		ch.NetworkAdapters = redfish.Link{ODataID: chassisPath + "/NetworkAdapters"}
+		ch.Links.ComputerSystems = []redfish.Link{{ODataID: systemPath}}
+		ch.Links.ManagedBy = []redfish.Link{{ODataID: managerPath}}
+		docs[ch.NetworkAdapters.ODataID] = collection(ch.NetworkAdapters.ODataID, "Network Adapter Collection", adapters)
+		if len(detail.TrustedComponents) > 0 {
+			ch.TrustedComponents = redfish.Link{ODataID: chassisPath + "/TrustedComponents"}
+			var components []redfish.Link
+			for j, c := range detail.TrustedComponents {
+				path := fmt.Sprintf("%s/%d", ch.TrustedComponents.ODataID, j+1)
+				components = append(components, redfish.Link{ODataID: path})
+				docs[path] = trustedComponent(path, fmt.Sprint(j+1), c, docs)
+			}
+			docs[ch.TrustedComponents.ODataID] = collection(ch.TrustedComponents.ODataID, "Trusted Component Collection", components)
+		}
+
+		child := &ch
+		for j, parent := range cd.Ancestors() {
+			id := fmt.Sprintf("%d-Enclosure%d", i+1, j+1)
+			path := "/redfish/v1/Chassis/" + id
+			chassis = append(chassis, redfish.Link{ODataID: path})
+			p := chassisDocument(path, id, parent)
+			p.Links.Contains = []redfish.Link{{ODataID: child.ODataID}}
+			child.Links.ContainedBy = redfish.Link{ODataID: path}
+			docs[child.ODataID] = *child
+			child = &p
+		}
+		docs[child.ODataID] = *child
+	}
+	docs["/redfish/v1/Systems"] = collection("/redfish/v1/Systems", "Computer System Collection", systems)
+	docs["/redfish/v1/Chassis"] = collection("/redfish/v1/Chassis", "Chassis Collection", chassis)
+
+	docs["/redfish/v1/Managers"] = collection("/redfish/v1/Managers", "Manager Collection", []redfish.Link{{ODataID: managerPath}})
+	docs[managerPath] = redfish.Manager{
+		ODataID:            managerPath,
+		ID:                 "BMC",
+		Name:               "Manager",
+		ManagerType:        "BMC",
+		UUID:               ep.UID.String(),
+		EthernetInterfaces: redfish.Link{ODataID: managerPath + "/EthernetInterfaces"},
+		Status:             redfish.Status{State: "Enabled", Health: "OK"},
+	}
+	bmcInterface := managerPath + "/EthernetInterfaces/1"
+	docs[managerPath+"/EthernetInterfaces"] = collection(managerPath+"/EthernetInterfaces", "Ethernet Interface Collection", []redfish.Link{{ODataID: bmcInterface}})
+	docs[bmcInterface] = ethernetInterface(bmcInterface, "1", schemas.EthernetInterface{
+		Name:    "Manager Ethernet Interface",
+		MAC:     ep.MACAddr,
+		IP:      ep.IPAddress,
+		Enabled: schemas.Some(true),
+	})
+
+	return docs
+}
+
+func chassisDocument(path, id string, c schemas.ChassisDetail) redfish.Chassis {
+	chassisType := c.Type
+	if chassisType == "" {
+		chassisType = "RackMount"
+	}
+	return redfish.Chassis{
+		ODataID:      path,
+		ID:           id,
+		Name:         "Chassis",
+		ChassisType:  chassisType,
+		Manufacturer: c.Manufacturer,
+		Model:        c.Model,
+		SerialNumber: c.Serial,
+		SKU:          c.SKU,
+		AssetTag:     c.AssetTag,
+		Status:       redfish.Status{State: "Enabled", Health: "OK"},
+	}
+}
+
+// trustedComponent builds a TrustedComponent document, adding a Certificates
+// collection to docs if it has any.
+func trustedComponent(path, id string, c schemas.TrustedComponent, docs map[string]interface{}) redfish.TrustedComponent {
+	tc := redfish.TrustedComponent{
+		ODataID:              path,
+		ID:                   id,
+		Name:                 "Trusted Component",
+		TrustedComponentType: c.Type,
+		Manufacturer:         c.Manufacturer,
+		Model:                c.Model,
+		SerialNumber:         c.Serial,
+		UUID:                 c.UUID,
+		FirmwareVersion:      c.Firmware,
+		Status:               redfish.Status{State: c.Status},
+	}
+	if len(c.Certificates) == 0 {
+		return tc
+	}
+	tc.Certificates = redfish.Link{ODataID: path + "/Certificates"}
+	var certs []redfish.Link
+	for k, pem := range c.Certificates {
+		certPath := fmt.Sprintf("%s/%d", tc.Certificates.ODataID, k+1)
+		certs = append(certs, redfish.Link{ODataID: certPath})
+		docs[certPath] = redfish.Certificate{ODataID: certPath, ID: fmt.Sprint(k + 1), CertificateString: pem, CertificateType: "PEM"}
+	}
+	docs[tc.Certificates.ODataID] = collection(tc.Certificates.ODataID, "Certificate Collection", certs)
+	return tc
+}
+
+func ethernetInterface(path, id string, e schemas.EthernetInterface) redfish.EthernetInterface {
+	ei := redfish.EthernetInterface{
+		ODataID:     path,
+		ID:          id,
+		Name:        e.Name,
+		Description: e.Description,
+		MACAddress:  e.MAC.String(),
+		LinkStatus:  e.LinkStatus,
+		SpeedMbps:   e.SpeedMbps,
+		MTUSize:     e.MTU,
+	}
+	if enabled, ok := e.Enabled.Get(); ok {
+		ei.InterfaceEnabled = &enabled
+	}
+	if len(e.VLANs) > 0 {
+		ei.VLAN = &redfish.VLAN{VLANId: e.VLANs[0].ID, VLANEnable: e.VLANs[0].Enabled.OrElse(true)}
+	}
+	addresses := e.Addresses
+	if len(addresses) == 0 && e.IP.IsValid() {
+		addresses = []schemas.InterfaceAddress{{Address: e.IP}}
+	}
+	for _, a := range addresses {
+		switch {
+		case a.Address.Is6():
+			ei.IPv6Addresses = append(ei.IPv6Addresses, redfish.IPv6Address{Address: a.Address.String(), PrefixLength: a.PrefixLength, AddressOrigin: a.Origin})
+		case a.Address.Is4():
+			v4 := redfish.IPv4Address{Address: a.Address.String(), AddressOrigin: a.Origin, Gateway: a.Gateway.String()}
+			if a.PrefixLength > 0 {
+				v4.SubnetMask = net.IP(net.CIDRMask(a.PrefixLength, 32)).String()
+			}
+			ei.IPv4Addresses = append(ei.IPv4Addresses, v4)
+		}
+	}
+	return ei
+}
+
+func collection(odataID, name string, members []redfish.Link) redfish.Collection {
+	if members == nil {
+		members = []redfish.Link{}
+	}
+	return redfish.Collection{ODataID: odataID, Name: name, Members: members, Count: len(members)}
+}
@@ -0,0 +1,176 @@
+package redfishtest
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+	"github.com/openchami/schemas/schemas/redfish"
+)
+
+func fixture() (csm.RedfishEndpoint, []schemas.InventoryDetail) {
+	ep := csm.RedfishEndpoint{
+		ID:       "x1000c0s0b0",
+		User:     "root",
+		Password: "hunter2",
+		UID:      uuid.MustParse("6c4c5e1a-4f2d-4b7a-9a57-1e3c2b6d0f11"),
+	}
+	ip, _ := schemas.ParseIPAddress("10.1.0.12")
+	mac, _ := schemas.ParseMACAddress("b0:7b:25:c8:2a:10")
+	inventory := []schemas.InventoryDetail{{
+		UUID:         "4c4c4544-0042-3610-8052-b3c04f333333",
+		Manufacturer: "Dell Inc.",
+		Model:        "PowerEdge R650",
+		Serial:       "CNIVC0012345",
+		PowerState:   "On",
+		EthernetInterfaces: []schemas.EthernetInterface{{
+			Name:      "NIC 1",
+			MAC:       mac,
+			IP:        ip,
+			Addresses: []schemas.InterfaceAddress{{Address: ip, PrefixLength: 16}},
+		}},
+		NetworkAdapters:   []schemas.NetworkAdapter{{ID: "NIC.Slot.1", Manufacturer: "Mellanox", Serial: "MT1234"}},
+		NetworkInterfaces: []schemas.NetworkInterface{{Name: "NIC 1", AdapterID: "NIC.Slot.1"}},
+		Processors:        []schemas.Processor{{Socket: "CPU 1", Model: "Xeon"}, {Socket: "CPU 2", Model: "Xeon"}},
+		Accelerators:      []schemas.Accelerator{{Type: "GPU", Model: "L4", Memory: 24 * schemas.GiB}},
+		MemoryModules:     []schemas.MemoryModule{{Name: "DIMM A1", Capacity: 32 * schemas.GiB}},
+		Drives:            []schemas.Drive{{Name: "SSD 0", Capacity: 480 * schemas.GB}},
+		PCIeDevices:       []schemas.PCIeDevice{{Name: "BCM57414"}},
+		TrustedModules:    []schemas.TrustedModule{{InterfaceType: "TPM2_0", Status: "Enabled"}},
+		TrustedComponents: []schemas.TrustedComponent{{Type: "Integrated", Certificates: []string{"-----BEGIN CERTIFICATE-----"}}},
+		Chassis: &schemas.ChassisDetail{
+			Type:   "Blade",
+			Serial: "BLADE1",
+			Parent: &schemas.ChassisDetail{Type: "Enclosure", Serial: "ENC1"},
+		},
+	}}
+	return ep, inventory
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	ep, inventory := fixture()
+	s := NewServer(ep, inventory)
+	defer s.Close()
+
+	result, err := redfish.Discover(s.Fetcher(), s.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Endpoint.UID != ep.UID || result.Endpoint.DiscoveryInfo.RedfishVersion != DefaultRedfishVersion {
+		t.Errorf("endpoint UID %s version %s", result.Endpoint.UID, result.Endpoint.DiscoveryInfo.RedfishVersion)
+	}
+	if len(result.Inventory) != 1 {
+		t.Fatalf("got %d systems, want 1", len(result.Inventory))
+	}
+
+	got, want := result.Inventory[0], inventory[0]
+	if got.UUID != want.UUID || got.Serial != want.Serial || got.PowerState != want.PowerState {
+		t.Errorf("system = %+v", got)
+	}
+	counts := []struct {
+		name      string
+		got, want int
+	}{
+		{"processors", len(got.Processors), 2},
+		{"accelerators", len(got.Accelerators), 1},
+		{"memory modules", len(got.MemoryModules), 1},
+		{"drives", len(got.Drives), 1},
+		{"PCIe devices", len(got.PCIeDevices), 1},
+		{"network adapters", len(got.NetworkAdapters), 1},
+		{"network interfaces", len(got.NetworkInterfaces), 1},
+		{"trusted modules", len(got.TrustedModules), 1},
+		{"trusted components", len(got.TrustedComponents), 1},
+	}
+	for _, c := range counts {
+		if c.got != c.want {
+			t.Errorf("%d %s, want %d", c.got, c.name, c.want)
+		}
+	}
+	if got.Accelerators[0].Memory != 24*schemas.GiB || got.Drives[0].Capacity != 480*schemas.GB {
+		t.Errorf("accelerator memory %s drive capacity %s", got.Accelerators[0].Memory, got.Drives[0].Capacity)
+	}
+	if e := got.EthernetInterfaces[0]; e.MAC != want.EthernetInterfaces[0].MAC || e.IP != want.EthernetInterfaces[0].IP || e.Addresses[0].PrefixLength != 16 {
+		t.Errorf("ethernet interface = %+v", e)
+	}
+	if got.NetworkInterfaces[0].AdapterID != "NIC.Slot.1" {
+		t.Errorf("network interface adapter %q, want NIC.Slot.1", got.NetworkInterfaces[0].AdapterID)
+	}
+	if got.Chassis == nil || got.Chassis.Serial != "BLADE1" || got.Chassis.Parent == nil || got.Chassis.Parent.Serial != "ENC1" {
+		t.Errorf("chassis = %+v", got.Chassis)
+	}
+}
+
+func TestServerInjectFailure(t *testing.T) {
+	tests := []struct {
+		inject csm.DiscoveryStatus
+		want   csm.DiscoveryStatus
+	}{
+		{csm.DiscoveryEndpointInvalid, csm.DiscoveryEndpointInvalid},
+		{csm.DiscoveryEPResponseFailedDecode, csm.DiscoveryEPResponseFailedDecode},
+		{csm.DiscoveryHTTPsGetFailed, csm.DiscoveryHTTPsGetFailed},
+		{csm.DiscoveryNotYetQueried, csm.DiscoveryHTTPsGetFailed},
+		{csm.DiscoveryVerificationFailed, csm.DiscoveryVerificationFailed},
+		{csm.DiscoveryChildVerificationFailed, csm.DiscoveryChildVerificationFailed},
+		{csm.DiscoveryOK, csm.DiscoveryOK},
+	}
+	ep, inventory := fixture()
+	s := NewServer(ep, inventory)
+	defer s.Close()
+	for _, tt := range tests {
+		t.Run(string(tt.inject), func(t *testing.T) {
+			s.InjectFailure(tt.inject)
+			result, err := redfish.Discover(s.Fetcher(), s.Endpoint)
+			if got := redfish.StatusOf(err); got != tt.want || result.Endpoint.DiscoveryInfo.LastStatus != tt.want {
+				t.Errorf("status %s (%v), want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerRequiresCredentials(t *testing.T) {
+	ep, inventory := fixture()
+	s := NewServer(ep, inventory)
+	defer s.Close()
+
+	f := s.Fetcher()
+	f.Password = "wrong"
+	if _, err := f.Fetch(redfish.ServiceRootPath); redfish.StatusOf(err) != csm.DiscoveryVerificationFailed {
+		t.Errorf("Fetch() with a wrong password = %v, want VerificationFailed", err)
+	}
+}
+
+func TestServerSetDocument(t *testing.T) {
+	ep, inventory := fixture()
+	s := NewServer(ep, inventory)
+	defer s.Close()
+
+	s.SetDocument(redfish.ServiceRootPath, redfish.ServiceRoot{RedfishVersion: "1.17.0"})
+	result, err := redfish.Discover(s.Fetcher(), s.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Endpoint.DiscoveryInfo.RedfishVersion != "1.17.0" || len(result.Inventory) != 0 {
+		t.Errorf("endpoint %+v with %d systems, want version 1.17.0 and no systems", result.Endpoint.DiscoveryInfo, len(result.Inventory))
+	}
+}
+
+func TestServerPinnedCertificate(t *testing.T) {
+	ep, inventory := fixture()
+	s := NewServer(ep, inventory)
+	defer s.Close()
+
+	s.Endpoint.TLSInfo = &csm.TLSInfo{Fingerprint: csm.CertificateFingerprint(s.Certificate()), Pinned: true}
+	result, err := redfish.Discover(s.Fetcher(), s.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := result.Endpoint.TLSInfo; info == nil || !info.Pinned || info.Fingerprint != s.Endpoint.TLSInfo.Fingerprint {
+		t.Errorf("TLSInfo = %+v, want the pinned certificate", info)
+	}
+
+	s.Endpoint.TLSInfo.Fingerprint = "0000"
+	if _, err := redfish.Discover(s.Fetcher(), s.Endpoint); redfish.StatusOf(err) != csm.DiscoveryVerificationFailed {
+		t.Errorf("Discover() with a mismatched pin = %v, want VerificationFailed", err)
+	}
+}
@@ -0,0 +1,266 @@
+package redfish
+
+// The types in this file are the subset of the DMTF Redfish schema that is
+// needed to populate RedfishEndpoint and InventoryDetail.
+
+// Link is a reference to another Redfish resource.
+type Link struct {
+	ODataID string `json:"@odata.id,omitempty"`
+}
+
+type Status struct {
+	State  string `json:"State,omitempty"`
+	Health string `json:"Health,omitempty"`
+}
+
+type ServiceRoot struct {
+	ODataID        string `json:"@odata.id,omitempty"`
+	ID             string `json:"Id,omitempty"`
+	Name           string `json:"Name,omitempty"`
+	RedfishVersion string `json:"RedfishVersion,omitempty"`
+	UUID           string `json:"UUID,omitempty"`
+	Systems        Link   `json:"Systems,omitempty"`
+	Chassis        Link   `json:"Chassis,omitempty"`
+	Managers       Link   `json:"Managers,omitempty"`
+}
+
+type Collection struct {
+	ODataID string `json:"@odata.id,omitempty"`
+	Name    string `json:"Name,omitempty"`
+	Members []Link `json:"Members"`
+	Count   int    `json:"Members@odata.count"`
+}
+
+type ProcessorSummary struct {
+	Count int    `json:"Count,omitempty"`
+	Model string `json:"Model,omitempty"`
+}
+
+type MemorySummary struct {
+	TotalSystemMemoryGiB float32 `json:"TotalSystemMemoryGiB,omitempty"`
+}
+
+type TrustedModule struct {
+	InterfaceType   string `json:"InterfaceType,omitempty"`
+	FirmwareVersion string `json:"FirmwareVersion,omitempty"`
+	Status          Status `json:"Status,omitempty"`
+}
+
+type SystemLinks struct {
+	Chassis   []Link `json:"Chassis,omitempty"`
+	ManagedBy []Link `json:"ManagedBy,omitempty"`
+}
+
+type ComputerSystem struct {
+	ODataID            string           `json:"@odata.id,omitempty"`
+	ID                 string           `json:"Id,omitempty"`
+	Name               string           `json:"Name,omitempty"`
+	Description        string           `json:"Description,omitempty"`
+	UUID               string           `json:"UUID,omitempty"`
+	Manufacturer       string           `json:"Manufacturer,omitempty"`
+	SystemType         string           `json:"SystemType,omitempty"`
+	Model              string           `json:"Model,omitempty"`
+	SerialNumber       string           `json:"SerialNumber,omitempty"`
+	SKU                string           `json:"SKU,omitempty"`
+	BiosVersion        string           `json:"BiosVersion,omitempty"`
+	PowerState         string           `json:"PowerState,omitempty"`
+	ProcessorSummary   ProcessorSummary `json:"ProcessorSummary,omitempty"`
+	MemorySummary      MemorySummary    `json:"MemorySummary,omitempty"`
+	TrustedModules     []TrustedModule  `json:"TrustedModules,omitempty"`
+	Processors         Link             `json:"Processors,omitempty"`
+	Memory             Link             `json:"Memory,omitempty"`
+	Storage            Link             `json:"Storage,omitempty"`
+	PCIeDevices        []Link           `json:"PCIeDevices,omitempty"`
+	EthernetInterfaces Link             `json:"EthernetInterfaces,omitempty"`
+	NetworkInterfaces  Link             `json:"NetworkInterfaces,omitempty"`
+	Links              SystemLinks      `json:"Links,omitempty"`
+	Status             Status           `json:"Status,omitempty"`
+}
+
+type ChassisLinks struct {
+	ComputerSystems []Link `json:"ComputerSystems,omitempty"`
+	ManagedBy       []Link `json:"ManagedBy,omitempty"`
+	ContainedBy     Link   `json:"ContainedBy,omitempty"`
+	Contains        []Link `json:"Contains,omitempty"`
+}
+
+type Chassis struct {
+	ODataID           string       `json:"@odata.id,omitempty"`
+	ID                string       `json:"Id,omitempty"`
+	Name              string       `json:"Name,omitempty"`
+	ChassisType       string       `json:"ChassisType,omitempty"`
+	Manufacturer      string       `json:"Manufacturer,omitempty"`
+	Model             string       `json:"Model,omitempty"`
+	SerialNumber      string       `json:"SerialNumber,omitempty"`
+	SKU               string       `json:"SKU,omitempty"`
+	AssetTag          string       `json:"AssetTag,omitempty"`
+	NetworkAdapters   Link         `json:"NetworkAdapters,omitempty"`
+	TrustedComponents Link         `json:"TrustedComponents,omitempty"`
+	Links             ChassisLinks `json:"Links,omitempty"`
+	Status            Status       `json:"Status,omitempty"`
+}
+
+type TrustedComponent struct {
+	ODataID              string `json:"@odata.id,omitempty"`
+	ID                   string `json:"Id,omitempty"`
+	Name                 string `json:"Name,omitempty"`
+	TrustedComponentType string `json:"TrustedComponentType,omitempty"`
+	Manufacturer         string `json:"Manufacturer,omitempty"`
+	Model                string `json:"Model,omitempty"`
+	SerialNumber         string `json:"SerialNumber,omitempty"`
+	UUID                 string `json:"UUID,omitempty"`
+	FirmwareVersion      string `json:"FirmwareVersion,omitempty"`
+	Certificates         Link   `json:"Certificates,omitempty"`
+	Status               Status `json:"Status,omitempty"`
+}
+
+type Certificate struct {
+	ODataID           string `json:"@odata.id,omitempty"`
+	ID                string `json:"Id,omitempty"`
+	CertificateString string `json:"CertificateString,omitempty"`
+	CertificateType   string `json:"CertificateType,omitempty"`
+}
+
+type Processor struct {
+	ODataID               string            `json:"@odata.id,omitempty"`
+	ID                    string            `json:"Id,omitempty"`
+	Name                  string            `json:"Name,omitempty"`
+	Socket                string            `json:"Socket,omitempty"`
+	ProcessorType         string            `json:"ProcessorType,omitempty"`
+	ProcessorArchitecture string            `json:"ProcessorArchitecture,omitempty"`
+	InstructionSet        string            `json:"InstructionSet,omitempty"`
+	Manufacturer          string            `json:"Manufacturer,omitempty"`
+	Model                 string            `json:"Model,omitempty"`
+	SerialNumber          string            `json:"SerialNumber,omitempty"`
+	FirmwareVersion       string            `json:"FirmwareVersion,omitempty"`
+	TotalCores            int               `json:"TotalCores,omitempty"`
+	TotalThreads          int               `json:"TotalThreads,omitempty"`
+	MaxSpeedMHz           int               `json:"MaxSpeedMHz,omitempty"`
+	ProcessorMemory       []ProcessorMemory `json:"ProcessorMemory,omitempty"`
+	Status                Status            `json:"Status,omitempty"`
+}
+
+type ProcessorMemory struct {
+	CapacityMiB int    `json:"CapacityMiB,omitempty"`
+	MemoryType  string `json:"MemoryType,omitempty"`
+}
+
+type Memory struct {
+	ODataID           string `json:"@odata.id,omitempty"`
+	ID                string `json:"Id,omitempty"`
+	Name              string `json:"Name,omitempty"`
+	MemoryDeviceType  string `json:"MemoryDeviceType,omitempty"`
+	CapacityMiB       int    `json:"CapacityMiB,omitempty"`
+	OperatingSpeedMhz int    `json:"OperatingSpeedMhz,omitempty"`
+	Manufacturer      string `json:"Manufacturer,omitempty"`
+	PartNumber        string `json:"PartNumber,omitempty"`
+	SerialNumber      string `json:"SerialNumber,omitempty"`
+	FirmwareRevision  string `json:"FirmwareRevision,omitempty"`
+	Status            Status `json:"Status,omitempty"`
+}
+
+type Storage struct {
+	ODataID string `json:"@odata.id,omitempty"`
+	ID      string `json:"Id,omitempty"`
+	Name    string `json:"Name,omitempty"`
+	Drives  []Link `json:"Drives,omitempty"`
+	Status  Status `json:"Status,omitempty"`
+}
+
+type Drive struct {
+	ODataID       string `json:"@odata.id,omitempty"`
+	ID            string `json:"Id,omitempty"`
+	Name          string `json:"Name,omitempty"`
+	MediaType     string `json:"MediaType,omitempty"`
+	Protocol      string `json:"Protocol,omitempty"`
+	Manufacturer  string `json:"Manufacturer,omitempty"`
+	Model         string `json:"Model,omitempty"`
+	SerialNumber  string `json:"SerialNumber,omitempty"`
+	Revision      string `json:"Revision,omitempty"`
+	CapacityBytes int64  `json:"CapacityBytes,omitempty"`
+	Status        Status `json:"Status,omitempty"`
+}
+
+type PCIeDevice struct {
+	ODataID         string `json:"@odata.id,omitempty"`
+	ID              string `json:"Id,omitempty"`
+	Name            string `json:"Name,omitempty"`
+	DeviceType      string `json:"DeviceType,omitempty"`
+	Manufacturer    string `json:"Manufacturer,omitempty"`
+	Model           string `json:"Model,omitempty"`
+	PartNumber      string `json:"PartNumber,omitempty"`
+	SerialNumber    string `json:"SerialNumber,omitempty"`
+	FirmwareVersion string `json:"FirmwareVersion,omitempty"`
+	Status          Status `json:"Status,omitempty"`
+}
+
+type Manager struct {
+	ODataID            string `json:"@odata.id,omitempty"`
+	ID                 string `json:"Id,omitempty"`
+	Name               string `json:"Name,omitempty"`
+	ManagerType        string `json:"ManagerType,omitempty"`
+	UUID               string `json:"UUID,omitempty"`
+	Model              string `json:"Model,omitempty"`
+	FirmwareVersion    string `json:"FirmwareVersion,omitempty"`
+	EthernetInterfaces Link   `json:"EthernetInterfaces,omitempty"`
+	Status             Status `json:"Status,omitempty"`
+}
+
+type IPv4Address struct {
+	Address       string `json:"Address,omitempty"`
+	SubnetMask    string `json:"SubnetMask,omitempty"`
+	AddressOrigin string `json:"AddressOrigin,omitempty"`
+	Gateway       string `json:"Gateway,omitempty"`
+}
+
+type IPv6Address struct {
+	Address       string `json:"Address,omitempty"`
+	PrefixLength  int    `json:"PrefixLength,omitempty"`
+	AddressOrigin string `json:"AddressOrigin,omitempty"`
+}
+
+type VLAN struct {
+	VLANEnable bool `json:"VLANEnable,omitempty"`
+	VLANId     int  `json:"VLANId,omitempty"`
+}
+
+type EthernetInterface struct {
+	ODataID             string        `json:"@odata.id,omitempty"`
+	ID                  string        `json:"Id,omitempty"`
+	Name                string        `json:"Name,omitempty"`
+	Description         string        `json:"Description,omitempty"`
+	MACAddress          string        `json:"MACAddress,omitempty"`
+	PermanentMACAddress string        `json:"PermanentMACAddress,omitempty"`
+	InterfaceEnabled    *bool         `json:"InterfaceEnabled,omitempty"`
+	LinkStatus          string        `json:"LinkStatus,omitempty"`
+	SpeedMbps           int           `json:"SpeedMbps,omitempty"`
+	MTUSize             int           `json:"MTUSize,omitempty"`
+	VLAN                *VLAN         `json:"VLAN,omitempty"`
+	IPv4Addresses       []IPv4Address `json:"IPv4Addresses,omitempty"`
+	IPv6Addresses       []IPv6Address `json:"IPv6Addresses,omitempty"`
+	Status              Status        `json:"Status,omitempty"`
+}
+
+type NetworkAdapter struct {
+	ODataID      string `json:"@odata.id,omitempty"`
+	ID           string `json:"Id,omitempty"`
+	Name         string `json:"Name,omitempty"`
+	Description  string `json:"Description,omitempty"`
+	Manufacturer string `json:"Manufacturer,omitempty"`
+	Model        string `json:"Model,omitempty"`
+	SerialNumber string `json:"SerialNumber,omitempty"`
+	Status       Status `json:"Status,omitempty"`
+}
+
+type NetworkInterfaceLinks struct {
+	NetworkAdapter Link `json:"NetworkAdapter,omitempty"`
+}
+
+type NetworkInterface struct {
+	ODataID     string                `json:"@odata.id,omitempty"`
+	ID          string                `json:"Id,omitempty"`
+	Name        string                `json:"Name,omitempty"`
+	Description string                `json:"Description,omitempty"`
+	Links       NetworkInterfaceLinks `json:"Links,omitempty"`
+	Status      Status                `json:"Status,omitempty"`
+}
@@ -0,0 +1,291 @@
+// Package ssdp parses the SSDP messages that Redfish services send to
+// announce themselves (DSP0266 "Discovery") and turns them into candidate
+// RedfishEndpoint values.
+package ssdp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+// RedfishServiceType is the search target announced by Redfish services.
+// Services may append a minor version, e.g. urn:dmtf-org:service:redfish-rest:1:0.
+const RedfishServiceType = "urn:dmtf-org:service:redfish-rest:1"
+
+// MulticastAddress is the IPv4 SSDP multicast group and port.
+const MulticastAddress = "239.255.255.250:1900"
+
+// Kind identifies which SSDP message was received.
+type Kind string
+
+const (
+	KindNotify   Kind = "NOTIFY"   // An unsolicited announcement
+	KindSearch   Kind = "M-SEARCH" // A search request from a client
+	KindResponse Kind = "RESPONSE" // A unicast reply to an M-SEARCH
+)
+
+// Notification subtypes carried in the NTS header.
+const (
+	Alive  = "ssdp:alive"
+	ByeBye = "ssdp:byebye"
+)
+
+// Message is a parsed SSDP message.
+type Message struct {
+	Kind     Kind
+	ST       string        // Search target, from ST on responses and searches or NT on notifications
+	NTS      string        // Notification subtype, NOTIFY only
+	USN      string        // Unique service name, e.g. uuid:<UUID>::urn:dmtf-org:service:redfish-rest:1
+	UUID     uuid.UUID     // UUID taken from USN, or uuid.Nil
+	AL       string        // URL of the Redfish service root
+	Location string        // LOCATION header, used when AL is absent
+	MaxAge   time.Duration // From CACHE-CONTROL max-age
+	Server   string
+	Source   net.Addr // Sender of the message, when read from a socket
+}
+
+// Parse parses a single SSDP datagram.
+func Parse(data []byte) (Message, error) {
+	// Some BMCs omit the blank line that terminates the header block.
+	if !bytes.HasSuffix(data, []byte("\r\n\r\n")) && !bytes.HasSuffix(data, []byte("\n\n")) {
+		data = append(append([]byte(nil), data...), "\r\n\r\n"...)
+	}
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	var m Message
+	var header http.Header
+	if bytes.HasPrefix(data, []byte("HTTP/")) {
+		resp, err := http.ReadResponse(r, nil)
+		if err != nil {
+			return Message{}, fmt.Errorf("invalid SSDP response: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return Message{}, fmt.Errorf("SSDP response has status %s", resp.Status)
+		}
+		m.Kind = KindResponse
+		header = resp.Header
+		m.ST = header.Get("ST")
+	} else {
+		req, err := http.ReadRequest(r)
+		if err != nil {
+			return Message{}, fmt.Errorf("invalid SSDP request: %w", err)
+		}
+		header = req.Header
+		switch req.Method {
+		case "NOTIFY":
+			m.Kind = KindNotify
+			m.ST = header.Get("NT")
+			m.NTS = header.Get("NTS")
+		case "M-SEARCH":
+			m.Kind = KindSearch
+			m.ST = header.Get("ST")
+		default:
+			return Message{}, fmt.Errorf("unknown SSDP method %q", req.Method)
+		}
+	}
+
+	m.USN = header.Get("USN")
+	m.AL = header.Get("AL")
+	m.Location = header.Get("LOCATION")
+	m.Server = header.Get("SERVER")
+	m.MaxAge = maxAge(header.Get("CACHE-CONTROL"))
+	if id, ok := strings.CutPrefix(m.USN, "uuid:"); ok {
+		id, _, _ = strings.Cut(id, "::")
+		if parsed, err := uuid.Parse(id); err == nil {
+			m.UUID = parsed
+		}
+	}
+	return m, nil
+}
+
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// IsRedfish reports whether the message concerns a Redfish service.
+func (m Message) IsRedfish() bool {
+	return m.ST == RedfishServiceType || strings.HasPrefix(m.ST, RedfishServiceType+":")
+}
+
+// ServiceRoot returns the service root URL from AL, falling back to LOCATION.
+func (m Message) ServiceRoot() string {
+	if m.AL != "" {
+		return m.AL
+	}
+	return m.Location
+}
+
+// Endpoint builds a candidate RedfishEndpoint from an announcement.  The
+// candidate has no ID or credentials; it records where the service was found
+// and is marked UseSSDP.
+func (m Message) Endpoint() (csm.RedfishEndpoint, error) {
+	if !m.IsRedfish() {
+		return csm.RedfishEndpoint{}, fmt.Errorf("not a Redfish service: %q", m.ST)
+	}
+	root := m.ServiceRoot()
+	if root == "" {
+		return csm.RedfishEndpoint{}, fmt.Errorf("announcement %s has no AL or LOCATION", m.USN)
+	}
+	u, err := url.Parse(root)
+	if err != nil || u.Hostname() == "" {
+		return csm.RedfishEndpoint{}, fmt.Errorf("announcement %s has invalid service root %q", m.USN, root)
+	}
+
+	ep := csm.RedfishEndpoint{
+		URI:     root,
+		UID:     m.UUID,
+		Enabled: schemas.Some(true),
+		UseSSDP: schemas.Some(true),
+	}
+	host := u.Hostname()
+	if ip, err := schemas.ParseIPAddress(host); err == nil {
+		ep.IPAddress = ip
+	} else {
+		ep.FQDN = host
+		ep.Hostname, ep.Domain, _ = strings.Cut(host, ".")
+	}
+	return ep, nil
+}
+
+// Candidates converts Redfish announcements into candidate endpoints,
+// ignoring searches and other services.  Candidates are deduplicated by UUID, or by service
+// root for announcements without one, with later messages replacing earlier
+// ones.  A byebye notification removes the service from the result.
+func Candidates(messages []Message) []csm.RedfishEndpoint {
+	var candidates []csm.RedfishEndpoint
+	index := map[string]int{}
+	removed := map[string]bool{}
+	for _, m := range messages {
+		if m.Kind == KindSearch || !m.IsRedfish() {
+			continue
+		}
+		key := m.ServiceRoot()
+		if m.UUID != uuid.Nil {
+			key = m.UUID.String()
+		}
+		if m.NTS == ByeBye {
+			removed[key] = true
+			continue
+		}
+		ep, err := m.Endpoint()
+		if err != nil {
+			continue
+		}
+		delete(removed, key)
+		if i, ok := index[key]; ok {
+			candidates[i] = ep
+			continue
+		}
+		index[key] = len(candidates)
+		candidates = append(candidates, ep)
+	}
+
+	result := candidates[:0]
+	for _, ep := range candidates {
+		key := ep.URI
+		if ep.UID != uuid.Nil {
+			key = ep.UID.String()
+		}
+		if !removed[key] {
+			result = append(result, ep)
+		}
+	}
+	return result
+}
+
+// Merge folds candidates into the known endpoints.  A candidate matches a
+// known endpoint with the same UUID, or failing that the same IP address or
+// FQDN.  Matched endpoints that have UseSSDP set take the candidate's URI,
+// address and UUID; everything else about them, including credentials, is
+// kept.  Candidates matching no known endpoint are returned as added.
+func Merge(known, candidates []csm.RedfishEndpoint) (merged, added []csm.RedfishEndpoint) {
+	merged = append([]csm.RedfishEndpoint(nil), known...)
+	for _, c := range candidates {
+		i := match(merged, c)
+		if i < 0 {
+			added = append(added, c)
+			continue
+		}
+		ep := &merged[i]
+		if !ep.UseSSDP.OrElse(false) {
+			continue
+		}
+		ep.URI = c.URI
+		if c.UID != uuid.Nil {
+			ep.UID = c.UID
+		}
+		if c.IPAddress.IsValid() {
+			ep.IPAddress = c.IPAddress
+		}
+		if c.FQDN != "" {
+			ep.FQDN, ep.Hostname, ep.Domain = c.FQDN, c.Hostname, c.Domain
+		}
+	}
+	return merged, added
+}
+
+func match(known []csm.RedfishEndpoint, c csm.RedfishEndpoint) int {
+	if c.UID != uuid.Nil {
+		for i, ep := range known {
+			if ep.UID == c.UID {
+				return i
+			}
+		}
+	}
+	for i, ep := range known {
+		if c.IPAddress.IsValid() && ep.IPAddress == c.IPAddress {
+			return i
+		}
+		if c.FQDN != "" && strings.EqualFold(ep.FQDN, c.FQDN) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SearchRequest returns an M-SEARCH datagram for Redfish services.  mx is the
+// number of seconds services may wait before responding.
+func SearchRequest(mx int) []byte {
+	return []byte("M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + MulticastAddress + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: " + strconv.Itoa(mx) + "\r\n" +
+		"ST: " + RedfishServiceType + "\r\n" +
+		"\r\n")
+}
+
+// ReadMessage reads and parses one datagram from conn, recording its sender.
+func ReadMessage(conn net.PacketConn) (Message, error) {
+	buf := make([]byte, 8192)
+	n, addr, err := conn.ReadFrom(buf)
+	if err != nil {
+		return Message{}, err
+	}
+	m, err := Parse(buf[:n])
+	if err != nil {
+		return Message{}, fmt.Errorf("from %s: %w", addr, err)
+	}
+	m.Source = addr
+	return m, nil
+}
@@ -0,0 +1,169 @@
+package ssdp
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openchami/schemas/schemas"
+	"github.com/openchami/schemas/schemas/csm"
+)
+
+func readPacket(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file    string
+		kind    Kind
+		nts     string
+		uuid    string
+		root    string
+		maxAge  time.Duration
+		redfish bool
+	}{
+		{"notify_alive.txt", KindNotify, Alive, "3256444f-c0b7-4a80-8050-00d04f434c4c", "https://10.254.1.12/redfish/v1/", 30 * time.Minute, true},
+		{"notify_byebye.txt", KindNotify, ByeBye, "3256444f-c0b7-4a80-8050-00d04f434c4c", "", 0, true},
+		{"msearch.txt", KindSearch, "", "", "", 0, true},
+		{"msearch_response.txt", KindResponse, "", "d4fcf66d-4a1c-5b5e-a1a8-4e3e67e4b1a2", "https://ilo-x1000c0s1b0.mgmt/redfish/v1/", 30 * time.Minute, true},
+		{"msearch_response_other.txt", KindResponse, "", "9a3b1c2d-0000-4000-8000-00112233aabb", "http://10.254.3.40:49152/description.xml", 2 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			m, err := Parse(readPacket(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			id := ""
+			if m.UUID != uuid.Nil {
+				id = m.UUID.String()
+			}
+			if m.Kind != tt.kind || m.NTS != tt.nts || id != tt.uuid || m.ServiceRoot() != tt.root || m.MaxAge != tt.maxAge || m.IsRedfish() != tt.redfish {
+				t.Errorf("Parse() = %+v", m)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"GET / HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\n\r\n",
+		"HTTP/1.1 404 Not Found\r\n\r\n",
+	} {
+		if m, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", data, m)
+		}
+	}
+}
+
+func TestMessageEndpoint(t *testing.T) {
+	m, err := Parse(readPacket(t, "msearch_response.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := m.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.FQDN != "ilo-x1000c0s1b0.mgmt" || ep.Hostname != "ilo-x1000c0s1b0" || ep.Domain != "mgmt" || ep.IPAddress.IsValid() || !ep.UseSSDP.OrElse(false) {
+		t.Errorf("Endpoint() = %+v", ep)
+	}
+
+	other, err := Parse(readPacket(t, "msearch_response_other.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Endpoint(); err == nil {
+		t.Error("Endpoint() of a non-Redfish service succeeded")
+	}
+}
+
+// TestLoopback sends the captured packets over a loopback socket and checks
+// that ReadMessage parses them and Candidates de-duplicates the result.
+func TestLoopback(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("no loopback UDP: %v", err)
+	}
+	defer conn.Close()
+	sender, err := net.DialUDP("udp4", nil, conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Close()
+
+	files := []string{
+		"notify_alive.txt",
+		"msearch.txt",
+		"msearch_response.txt",
+		"msearch_response_other.txt",
+		"notify_alive.txt",
+		"msearch_response.txt",
+	}
+	for _, name := range files {
+		if _, err := sender.Write(readPacket(t, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var messages []Message
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for range files {
+		m, err := ReadMessage(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Source == nil || m.Source.String() != sender.LocalAddr().String() {
+			t.Errorf("Source = %v, want %v", m.Source, sender.LocalAddr())
+		}
+		messages = append(messages, m)
+	}
+
+	candidates := Candidates(messages)
+	if len(candidates) != 2 {
+		t.Fatalf("Candidates() = %+v, want the iDRAC and the iLO once each", candidates)
+	}
+	if candidates[0].IPAddress != schemas.MustParseIPAddress("10.254.1.12") || candidates[1].FQDN != "ilo-x1000c0s1b0.mgmt" {
+		t.Errorf("Candidates() = %+v", candidates)
+	}
+
+	byebye, err := Parse(readPacket(t, "notify_byebye.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Candidates(append(messages, byebye)); len(got) != 1 || got[0].FQDN != "ilo-x1000c0s1b0.mgmt" {
+		t.Errorf("Candidates() after byebye = %+v, want only the iLO", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	known := []csm.RedfishEndpoint{
+		{ID: "x1000c0s0b0", IPAddress: schemas.MustParseIPAddress("10.254.1.12"), User: "root", UseSSDP: schemas.Some(true)},
+		{ID: "x1000c0s1b0", FQDN: "ILO-X1000C0S1B0.mgmt"},
+	}
+	candidates := []csm.RedfishEndpoint{
+		{URI: "https://10.254.1.12/redfish/v1/", IPAddress: schemas.MustParseIPAddress("10.254.1.12"), UseSSDP: schemas.Some(true)},
+		{URI: "https://ilo-x1000c0s1b0.mgmt/redfish/v1/", FQDN: "ilo-x1000c0s1b0.mgmt", UseSSDP: schemas.Some(true)},
+		{URI: "https://10.254.1.99/redfish/v1/", IPAddress: schemas.MustParseIPAddress("10.254.1.99"), UseSSDP: schemas.Some(true)},
+	}
+	merged, added := Merge(known, candidates)
+	if merged[0].URI != candidates[0].URI || merged[0].User != "root" {
+		t.Errorf("UseSSDP endpoint = %+v, want the candidate's URI and the known credentials", merged[0])
+	}
+	if merged[1].URI != "" {
+		t.Errorf("endpoint without UseSSDP = %+v, want it unchanged", merged[1])
+	}
+	if len(added) != 1 || added[0].IPAddress != schemas.MustParseIPAddress("10.254.1.99") {
+		t.Errorf("added = %+v", added)
+	}
+}
@@ -0,0 +1,218 @@
+package tabular
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+// column maps one spreadsheet column to a field of T.  set is only called
+// with non-empty values.
+type column[T any] struct {
+	name string
+	get  func(*T) string
+	set  func(*T, string) error
+}
+
+// nodeColumns are repeated on every row of a node.  Columns are written in
+// this order, so new ones should be added rather than existing ones moved.
+var nodeColumns = []column[schemas.InventoryDetail]{
+	text("uuid", func(d *schemas.InventoryDetail) *string { return &d.UUID }),
+	text("serial", func(d *schemas.InventoryDetail) *string { return &d.Serial }),
+	text("name", func(d *schemas.InventoryDetail) *string { return &d.Name }),
+	text("manufacturer", func(d *schemas.InventoryDetail) *string { return &d.Manufacturer }),
+	text("model", func(d *schemas.InventoryDetail) *string { return &d.Model }),
+	text("system_type", func(d *schemas.InventoryDetail) *string { return &d.SystemType }),
+	text("uri", func(d *schemas.InventoryDetail) *string { return &d.URI }),
+	text("bios_version", func(d *schemas.InventoryDetail) *string { return &d.BiosVersion }),
+	text("power_state", func(d *schemas.InventoryDetail) *string { return &d.PowerState }),
+	integer("processor_count", func(d *schemas.InventoryDetail) *int { return &d.ProcessorCount }),
+	text("processor_type", func(d *schemas.InventoryDetail) *string { return &d.ProcessorType }),
+	{
+		name: "memory",
+		get: func(d *schemas.InventoryDetail) string {
+			if d.Memory == 0 {
+				return ""
+			}
+			return d.Memory.String()
+		},
+		set: func(d *schemas.InventoryDetail, s string) (err error) {
+			d.Memory, err = schemas.ParseByteQuantity(s)
+			return err
+		},
+	},
+	chassis("chassis_type", func(c *schemas.ChassisDetail) *string { return &c.Type }),
+	chassis("chassis_sku", func(c *schemas.ChassisDetail) *string { return &c.SKU }),
+	chassis("chassis_serial", func(c *schemas.ChassisDetail) *string { return &c.Serial }),
+	chassis("chassis_asset_tag", func(c *schemas.ChassisDetail) *string { return &c.AssetTag }),
+	chassis("chassis_manufacturer", func(c *schemas.ChassisDetail) *string { return &c.Manufacturer }),
+	chassis("chassis_model", func(c *schemas.ChassisDetail) *string { return &c.Model }),
+}
+
+// interfaceColumns describe one EthernetInterface per row.
+var interfaceColumns = []column[schemas.EthernetInterface]{
+	text("interface_name", func(e *schemas.EthernetInterface) *string { return &e.Name }),
+	{
+		name: "interface_mac",
+		get:  func(e *schemas.EthernetInterface) string { return e.MAC.String() },
+		set: func(e *schemas.EthernetInterface, s string) (err error) {
+			e.MAC, err = schemas.ParseMACAddress(s)
+			return err
+		},
+	},
+	{
+		name: "interface_ip",
+		get:  func(e *schemas.EthernetInterface) string { return e.IP.String() },
+		set: func(e *schemas.EthernetInterface, s string) (err error) {
+			e.IP, err = schemas.ParseIPAddress(s)
+			return err
+		},
+	},
+	text("interface_uri", func(e *schemas.EthernetInterface) *string { return &e.URI }),
+	{
+		name: "interface_enabled",
+		get: func(e *schemas.EthernetInterface) string {
+			if enabled, ok := e.Enabled.Get(); ok {
+				return strconv.FormatBool(enabled)
+			}
+			return ""
+		},
+		set: func(e *schemas.EthernetInterface, s string) error {
+			enabled, err := strconv.ParseBool(s)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", s)
+			}
+			e.Enabled = schemas.Some(enabled)
+			return nil
+		},
+	},
+	text("interface_link_status", func(e *schemas.EthernetInterface) *string { return &e.LinkStatus }),
+	integer("interface_speed_mbps", func(e *schemas.EthernetInterface) *int { return &e.SpeedMbps }),
+	integer("interface_mtu", func(e *schemas.EthernetInterface) *int { return &e.MTU }),
+	{
+		name: "interface_vlans",
+		get: func(e *schemas.EthernetInterface) string {
+			ids := make([]string, len(e.VLANs))
+			for i, v := range e.VLANs {
+				ids[i] = strconv.Itoa(v.ID)
+			}
+			return strings.Join(ids, listSeparator)
+		},
+		set: func(e *schemas.EthernetInterface, s string) error {
+			for _, item := range splitList(s) {
+				id, err := strconv.Atoi(item)
+				if err != nil || id < 1 || id > 4094 {
+					return fmt.Errorf("invalid VLAN ID %q", item)
+				}
+				e.VLANs = append(e.VLANs, schemas.VLAN{ID: id})
+			}
+			return nil
+		},
+	},
+	{
+		name: "interface_addresses",
+		get: func(e *schemas.EthernetInterface) string {
+			addresses := make([]string, len(e.Addresses))
+			for i, a := range e.Addresses {
+				addresses[i] = a.Address.String()
+				if a.PrefixLength > 0 {
+					addresses[i] += "/" + strconv.Itoa(a.PrefixLength)
+				}
+			}
+			return strings.Join(addresses, listSeparator)
+		},
+		set: func(e *schemas.EthernetInterface, s string) error {
+			for _, item := range splitList(s) {
+				address, err := parseAddress(item)
+				if err != nil {
+					return err
+				}
+				e.Addresses = append(e.Addresses, address)
+			}
+			return nil
+		},
+	},
+}
+
+// listSeparator joins the values of list columns.  Commas are avoided so that
+// cells do not need quoting in CSV.
+const listSeparator = ";"
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, listSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseAddress parses an address with an optional prefix length, e.g.
+// 10.254.2.10/24 or fe80::1.
+func parseAddress(s string) (schemas.InterfaceAddress, error) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return schemas.InterfaceAddress{Address: schemas.IPAddressFrom(prefix.Addr()), PrefixLength: prefix.Bits()}, nil
+	}
+	ip, err := schemas.ParseIPAddress(s)
+	if err != nil {
+		return schemas.InterfaceAddress{}, err
+	}
+	return schemas.InterfaceAddress{Address: ip}, nil
+}
+
+func text[T any](name string, field func(*T) *string) column[T] {
+	return column[T]{
+		name: name,
+		get:  func(v *T) string { return *field(v) },
+		set: func(v *T, s string) error {
+			*field(v) = s
+			return nil
+		},
+	}
+}
+
+// integer maps an int field, leaving the cell empty for 0 as JSON omits it.
+func integer[T any](name string, field func(*T) *int) column[T] {
+	return column[T]{
+		name: name,
+		get: func(v *T) string {
+			if *field(v) == 0 {
+				return ""
+			}
+			return strconv.Itoa(*field(v))
+		},
+		set: func(v *T, s string) error {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid number %q", s)
+			}
+			*field(v) = n
+			return nil
+		},
+	}
+}
+
+// chassis maps a field of InventoryDetail.Chassis, allocating it when a value
+// is set.
+func chassis(name string, field func(*schemas.ChassisDetail) *string) column[schemas.InventoryDetail] {
+	return column[schemas.InventoryDetail]{
+		name: name,
+		get: func(d *schemas.InventoryDetail) string {
+			if d.Chassis == nil {
+				return ""
+			}
+			return *field(d.Chassis)
+		},
+		set: func(d *schemas.InventoryDetail, s string) error {
+			if d.Chassis == nil {
+				d.Chassis = &schemas.ChassisDetail{}
+			}
+			*field(d.Chassis) = s
+			return nil
+		},
+	}
+}
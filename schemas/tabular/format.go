@@ -0,0 +1,122 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openchami/schemas/schemas"
+	"gopkg.in/yaml.v3"
+)
+
+// WriteCSV writes details as CSV with a header of every column.
+func WriteCSV(w io.Writer, details []schemas.InventoryDetail) error {
+	columns := Columns()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range Flatten(details) {
+		for i, name := range columns {
+			record[i] = row[name]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads details from CSV whose first record is a header of column
+// names, in any order and with any columns left out.  Errors in the rows are
+// reported as for Unflatten.
+func ReadCSV(r io.Reader) ([]schemas.InventoryDetail, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	seen := map[string]bool{}
+	for i, name := range header {
+		// Spreadsheets often save UTF-8 with a byte order mark.
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !isColumn(name) {
+			return nil, fmt.Errorf("unknown column %q in CSV header", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %q in CSV header", name)
+		}
+		seen[name] = true
+		header[i] = name
+	}
+
+	var (
+		rows    []Row
+		invalid = map[int][]error{}
+	)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		row := make(Row, len(header))
+		for i, v := range record {
+			if i >= len(header) {
+				if strings.TrimSpace(v) != "" {
+					invalid[len(rows)+1] = []error{&RowError{Row: len(rows) + 1, Err: fmt.Errorf("%d cells but only %d columns", len(record), len(header))}}
+				}
+				break
+			}
+			row[header[i]] = v
+		}
+		rows = append(rows, row)
+	}
+
+	return unflatten(rows, invalid)
+}
+
+// WriteYAML writes details as a YAML sequence of rows, each a mapping with
+// its non-empty cells in column order.  Cells are double-quoted so that
+// values such as null, ~ or yes are read back as strings.
+func WriteYAML(w io.Writer, details []schemas.InventoryDetail) error {
+	columns := Columns()
+	doc := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, row := range Flatten(details) {
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, name := range columns {
+			if v, ok := row[name]; ok {
+				mapping.Content = append(mapping.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+					&yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: v})
+			}
+		}
+		doc.Content = append(doc.Content, mapping)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadYAML reads details from a YAML sequence of rows.  Errors in the rows
+// are reported as for Unflatten.
+func ReadYAML(r io.Reader) ([]schemas.InventoryDetail, error) {
+	var rows []Row
+	if err := yaml.NewDecoder(r).Decode(&rows); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read YAML: %w", err)
+	}
+	return Unflatten(rows)
+}
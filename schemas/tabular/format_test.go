@@ -0,0 +1,100 @@
+package tabular
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	in := testDetails()
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	header, _, _ := strings.Cut(buf.String(), "\n")
+	if header != strings.Join(Columns(), ",") {
+		t.Errorf("header = %q", header)
+	}
+	out, err := ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("ReadCSV(WriteCSV()) = %+v, want %+v", out, in)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		serials []string
+		ok      bool
+	}{
+		{"columns in any order", "model,serial\r\nR650,CN123\r\n,CN456\r\n", []string{"CN123", "CN456"}, true},
+		{"byte order mark", "\ufeffserial\nCN123\n", []string{"CN123"}, true},
+		{"empty", "", nil, true},
+		{"unknown column", "serial,colour\nCN123,blue\n", nil, false},
+		{"duplicate column", "serial,model,serial\nCN123,R650,CN456\n", nil, false},
+		{"extra cells", "serial\nCN123,R650\nCN456\n", []string{"CN456"}, false},
+		{"extra empty cells", "serial\nCN123,,\n", []string{"CN123"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ReadCSV(strings.NewReader(tt.in))
+			if (err == nil) != tt.ok {
+				t.Errorf("ReadCSV() error = %v", err)
+			}
+			var serials []string
+			for _, d := range out {
+				serials = append(serials, d.Serial)
+			}
+			if !reflect.DeepEqual(serials, tt.serials) {
+				t.Errorf("ReadCSV() serials = %v, want %v", serials, tt.serials)
+			}
+		})
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	in := testDetails()
+	// Cells that YAML would read as null or a boolean when unquoted.
+	in[1].Model = "null"
+	in[1].Manufacturer = "~"
+	in[1].SystemType = "yes"
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `model: "null"`) {
+		t.Errorf("WriteYAML() did not quote null:\n%s", buf.String())
+	}
+	out, err := ReadYAML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("ReadYAML(WriteYAML()) = %+v, want %+v", out, in)
+	}
+}
+
+func TestReadYAMLErrors(t *testing.T) {
+	in := "- serial: CN123\n  interface_ip: 10.1.0.300\n- serial: CN456\n"
+	out, err := ReadYAML(strings.NewReader(in))
+	if err == nil || !strings.Contains(err.Error(), "row 1: interface_ip") {
+		t.Errorf("ReadYAML() error = %v, want one for the IP of row 1", err)
+	}
+	if len(out) != 1 || out[0].Serial != "CN456" {
+		t.Errorf("ReadYAML() = %+v, want only CN456", out)
+	}
+
+	if _, err := ReadYAML(strings.NewReader("serial: CN123\n")); err == nil {
+		t.Error("ReadYAML() of a mapping rather than a sequence succeeded")
+	}
+	if out, err := ReadYAML(strings.NewReader("")); err != nil || out != nil {
+		t.Errorf("ReadYAML() of nothing = %+v, %v", out, err)
+	}
+}
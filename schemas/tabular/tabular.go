@@ -0,0 +1,286 @@
+// Package tabular converts InventoryDetail to and from flat rows, so that
+// inventory can be exchanged with spreadsheets as CSV or YAML.
+//
+// Each row holds the node columns and at most one Ethernet interface, so a
+// node is written as one row per interface with the node columns repeated,
+// or as a single row if it has none.  On import, rows are grouped into nodes
+// by uuid, or by serial for rows without one.  Sub-inventories (processors,
+// memory modules, drives, accelerators, PCIe devices and network adapters),
+// trusted modules and chassis parents are not represented, nor are VLAN
+// states or address origins and gateways; Dropped reports which of these a
+// detail has.
+package tabular
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+// Row is one record, keyed by column name.  Empty cells may be omitted.
+type Row map[string]string
+
+// RowError is a problem with one row.  Row is the 1-based position of the
+// record, not counting a CSV header.
+type RowError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("row %d: %s: %v", e.Row, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Columns returns every column name in the order they are written.
+func Columns() []string {
+	names := make([]string, 0, len(nodeColumns)+len(interfaceColumns))
+	for _, c := range nodeColumns {
+		names = append(names, c.name)
+	}
+	for _, c := range interfaceColumns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func isColumn(name string) bool {
+	for _, c := range nodeColumns {
+		if c.name == name {
+			return true
+		}
+	}
+	for _, c := range interfaceColumns {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Flatten converts details, in version 2 form, to rows.
+func Flatten(details []schemas.InventoryDetail) []Row {
+	var rows []Row
+	for _, d := range details {
+		d = d.ToV2()
+		node := Row{}
+		for _, c := range nodeColumns {
+			if v := c.get(&d); v != "" {
+				node[c.name] = v
+			}
+		}
+		if len(d.EthernetInterfaces) == 0 {
+			rows = append(rows, node)
+			continue
+		}
+		for _, e := range d.EthernetInterfaces {
+			row := make(Row, len(node)+len(interfaceColumns))
+			for k, v := range node {
+				row[k] = v
+			}
+			for _, c := range interfaceColumns {
+				if v := c.get(&e); v != "" {
+					row[c.name] = v
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Dropped returns the fields of d that Flatten leaves out, as sorted
+// DiffInventory paths without list keys, e.g. processors or
+// ethernet_interfaces.vlans.enabled.  It returns nil if d survives a round
+// trip through rows.
+func Dropped(d schemas.InventoryDetail) []string {
+	d = d.ToV2()
+	var back schemas.InventoryDetail
+	for i, row := range Flatten([]schemas.InventoryDetail{d}) {
+		node, e, hasInterface, _ := parseRow(i+1, row)
+		if i == 0 {
+			back = node
+		}
+		if hasInterface {
+			back.EthernetInterfaces = append(back.EthernetInterfaces, e)
+		}
+	}
+
+	var dropped []string
+	seen := map[string]bool{}
+	for _, c := range schemas.DiffInventory(d, back).Changes {
+		path := schemas.FieldPath(c.Path)
+		if !seen[path] {
+			seen[path] = true
+			dropped = append(dropped, path)
+		}
+	}
+	sort.Strings(dropped)
+	return dropped
+}
+
+// Unflatten groups rows into version 2 details, in the order each node first
+// appears.  Rows belong to the same node when they share a uuid or a serial.
+// Blank rows are skipped.  A row with any invalid cell, without a uuid or
+// serial, whose uuid and serial belong to different nodes, or whose node
+// columns conflict with an earlier row of the same node is left out, and a
+// RowError for it is included in the returned error; the details built from
+// the other rows are still returned.
+func Unflatten(rows []Row) ([]schemas.InventoryDetail, error) {
+	return unflatten(rows, nil)
+}
+
+// unflatten is Unflatten with errors already found in some rows, keyed by
+// their 1-based position, which cause those rows to be left out as well.
+func unflatten(rows []Row, invalid map[int][]error) ([]schemas.InventoryDetail, error) {
+	var (
+		details []schemas.InventoryDetail
+		index   = nodeIndex{uuids: map[string]int{}, serials: map[string]int{}}
+		errs    []error
+	)
+	for i, row := range rows {
+		n := i + 1
+		if isBlank(row) && len(invalid[n]) == 0 {
+			continue
+		}
+		d, e, hasInterface, rowErrs := parseRow(n, row)
+		rowErrs = append(invalid[n], rowErrs...)
+		if d.UUID == "" && d.Serial == "" {
+			rowErrs = append(rowErrs, &RowError{Row: n, Err: errors.New("uuid or serial is required")})
+		}
+		j, ok, err := index.find(d)
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Row: n, Column: "serial", Err: err})
+		}
+		if ok && len(rowErrs) == 0 {
+			rowErrs = mergeNode(n, &details[j], d)
+		}
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+
+		if !ok {
+			j = len(details)
+			details = append(details, d)
+		}
+		index.add(details[j], j)
+		if hasInterface {
+			details[j].EthernetInterfaces = append(details[j].EthernetInterfaces, e)
+		}
+	}
+	return details, errors.Join(errs...)
+}
+
+// nodeIndex finds the node a row belongs to by its uuid or its serial, the
+// same keys InventoryRequest.Process detects duplicates by, so that a row
+// with only the serial of a node and one with only its uuid are grouped
+// once a row has given both.
+type nodeIndex struct {
+	uuids   map[string]int
+	serials map[string]int
+}
+
+// find returns the position of the node with the uuid or serial of d.  It
+// returns an error if they belong to different nodes.
+func (x nodeIndex) find(d schemas.InventoryDetail) (int, bool, error) {
+	byUUID, uuidOK := x.uuids[strings.ToLower(d.UUID)]
+	bySerial, serialOK := x.serials[d.Serial]
+	switch {
+	case uuidOK && serialOK && byUUID != bySerial:
+		return 0, false, fmt.Errorf("uuid %s and serial %s belong to different nodes in earlier rows", d.UUID, d.Serial)
+	case uuidOK:
+		return byUUID, true, nil
+	case serialOK:
+		return bySerial, true, nil
+	}
+	return 0, false, nil
+}
+
+// add records the uuid and serial of the node at position j.
+func (x nodeIndex) add(d schemas.InventoryDetail, j int) {
+	if d.UUID != "" {
+		x.uuids[strings.ToLower(d.UUID)] = j
+	}
+	if d.Serial != "" {
+		x.serials[d.Serial] = j
+	}
+}
+
+func isBlank(row Row) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRow sets the node and interface fields from the non-empty cells of a
+// row, reporting every invalid cell and unknown column.
+func parseRow(n int, row Row) (schemas.InventoryDetail, schemas.EthernetInterface, bool, []error) {
+	var (
+		d            schemas.InventoryDetail
+		e            schemas.EthernetInterface
+		hasInterface bool
+		errs         []error
+	)
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !isColumn(name) {
+			errs = append(errs, &RowError{Row: n, Column: name, Err: errors.New("unknown column")})
+		}
+	}
+
+	for _, c := range nodeColumns {
+		if v := strings.TrimSpace(row[c.name]); v != "" {
+			if err := c.set(&d, v); err != nil {
+				errs = append(errs, &RowError{Row: n, Column: c.name, Err: err})
+			}
+		}
+	}
+	for _, c := range interfaceColumns {
+		if v := strings.TrimSpace(row[c.name]); v != "" {
+			hasInterface = true
+			if err := c.set(&e, v); err != nil {
+				errs = append(errs, &RowError{Row: n, Column: c.name, Err: err})
+			}
+		}
+	}
+	return d, e, hasInterface, errs
+}
+
+// mergeNode fills node columns that are empty in d from a later row of the
+// same node.  Nothing is changed if any column conflicts.
+func mergeNode(n int, d *schemas.InventoryDetail, from schemas.InventoryDetail) []error {
+	var errs []error
+	for _, c := range nodeColumns {
+		v, cur := c.get(&from), c.get(d)
+		if v != "" && cur != "" && !strings.EqualFold(v, cur) {
+			errs = append(errs, &RowError{Row: n, Column: c.name, Err: fmt.Errorf("%q conflicts with %q in an earlier row", v, cur)})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for _, c := range nodeColumns {
+		if v := c.get(&from); v != "" && c.get(d) == "" {
+			c.set(d, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package tabular
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openchami/schemas/schemas"
+)
+
+func testDetails() []schemas.InventoryDetail {
+	return []schemas.InventoryDetail{
+		{
+			UUID:           "4c4c4544-0042-3610-8052-b3c04f333333",
+			Serial:         "CN123",
+			Name:           "x1000c0s0b0n0",
+			Manufacturer:   "Dell Inc.",
+			Model:          "PowerEdge R650",
+			ProcessorCount: 2,
+			Memory:         512 * schemas.GiB,
+			Chassis:        &schemas.ChassisDetail{Type: "RackMount", Serial: "CH1"},
+			EthernetInterfaces: []schemas.EthernetInterface{
+				{
+					Name:       "eth0",
+					MAC:        schemas.MustParseMACAddress("b0:7b:25:c8:2a:10"),
+					IP:         schemas.MustParseIPAddress("10.254.1.12"),
+					Enabled:    schemas.Some(false),
+					LinkStatus: schemas.LinkUp,
+					SpeedMbps:  25000,
+					VLANs:      []schemas.VLAN{{ID: 10}, {ID: 20}},
+					Addresses:  []schemas.InterfaceAddress{{Address: schemas.MustParseIPAddress("10.254.1.12"), PrefixLength: 17}, {Address: schemas.MustParseIPAddress("fd00::12")}},
+				},
+				{Name: "eth1", MAC: schemas.MustParseMACAddress("b0:7b:25:c8:2a:11")},
+			},
+		},
+		{Serial: "CN456", Name: "x1000c0s1b0n0"},
+	}
+}
+
+func TestFlattenUnflatten(t *testing.T) {
+	in := testDetails()
+	rows := Flatten(in)
+	if len(rows) != 3 {
+		t.Fatalf("Flatten() = %d rows, want one per interface and one for the node without any", len(rows))
+	}
+	if rows[1]["uuid"] != in[0].UUID || rows[1]["interface_name"] != "eth1" || rows[0]["interface_enabled"] != "false" {
+		t.Errorf("Flatten() rows = %v", rows)
+	}
+	if rows[0]["interface_vlans"] != "10;20" || rows[0]["interface_addresses"] != "10.254.1.12/17;fd00::12" {
+		t.Errorf("list cells = %q and %q", rows[0]["interface_vlans"], rows[0]["interface_addresses"])
+	}
+
+	out, err := Unflatten(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Unflatten(Flatten()) = %+v, want %+v", out, in)
+	}
+}
+
+func TestUnflattenGrouping(t *testing.T) {
+	rows := []Row{
+		{"serial": "CN123", "interface_mac": "b0:7b:25:c8:2a:10"},
+		{},
+		{"serial": "CN456"},
+		{"serial": "CN123", "model": "R650", "interface_mac": "b0:7b:25:c8:2a:11"},
+	}
+	out, err := Unflatten(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out[0].Serial != "CN123" || out[1].Serial != "CN456" {
+		t.Fatalf("Unflatten() = %+v, want CN123 then CN456", out)
+	}
+	if out[0].Model != "R650" || len(out[0].EthernetInterfaces) != 2 {
+		t.Errorf("node CN123 = %+v, want the model and both interfaces", out[0])
+	}
+
+	// Rows with only the serial or only the uuid of a node join it once a
+	// row has given both.
+	uuid := "4c4c4544-0042-3610-8052-b3c04f333333"
+	rows = []Row{
+		{"serial": "CN123", "interface_mac": "b0:7b:25:c8:2a:10"},
+		{"serial": "CN123", "uuid": uuid},
+		{"uuid": strings.ToUpper(uuid), "interface_mac": "b0:7b:25:c8:2a:11"},
+	}
+	out, err = Unflatten(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].UUID != uuid || len(out[0].EthernetInterfaces) != 2 {
+		t.Errorf("Unflatten() = %+v, want one node with both interfaces", out)
+	}
+}
+
+func TestUnflattenRowErrors(t *testing.T) {
+	rows := []Row{
+		{"serial": "CN123", "model": "R650"},
+		{"serial": "CN123", "model": "R750"},
+		{"name": "x1000c0s1b0n0"},
+		{"serial": "CN456", "interface_mac": "not a mac", "processor_count": "-1"},
+		{"serial": "CN789", "colour": "blue"},
+		{"serial": "CN999", "interface_vlans": "10;5000"},
+		{"serial": "CN000", "interface_ip": "10.1.0.12"},
+		{"serial": "CN000", "uuid": "4c4c4544-0042-3610-8052-b3c04f333333"},
+		{"serial": "CN123", "uuid": "4c4c4544-0042-3610-8052-b3c04f333333"},
+	}
+	out, err := Unflatten(rows)
+	if len(out) != 2 || out[0].Serial != "CN123" || out[0].Model != "R650" || out[1].Serial != "CN000" {
+		t.Errorf("Unflatten() = %+v, want only the valid rows", out)
+	}
+
+	want := []RowError{
+		{Row: 2, Column: "model"},
+		{Row: 3},
+		{Row: 4, Column: "processor_count"},
+		{Row: 4, Column: "interface_mac"},
+		{Row: 5, Column: "colour"},
+		{Row: 6, Column: "interface_vlans"},
+		{Row: 9, Column: "serial"},
+	}
+	var got []RowError
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var re *RowError
+		if !errors.As(e, &re) {
+			t.Fatalf("error %v is not a RowError", e)
+		}
+		got = append(got, RowError{Row: re.Row, Column: re.Column})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("row errors = %+v, want %+v", got, want)
+	}
+}
+
+func TestDropped(t *testing.T) {
+	if got := Dropped(testDetails()[0]); got != nil {
+		t.Errorf("Dropped() of a detail with only columns = %v", got)
+	}
+
+	d := testDetails()[0]
+	d.Processors = []schemas.Processor{{Socket: "CPU 1"}}
+	d.NetworkInterfaces = []schemas.NetworkInterface{{URI: "/NetworkInterfaces/1"}}
+	d.TrustedModules = []schemas.TrustedModule{{InterfaceType: "TPM2_0"}}
+	d.EthernetInterfaces[0].VLANs[0].Enabled = schemas.Some(true)
+	want := []string{"ethernet_interfaces.vlans.enabled", "network_interfaces", "processors", "trusted_modules"}
+	if got := Dropped(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dropped() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package schemas
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Measurement is a reference to a measurement taken by a trusted module or
+// component, such as a PCR, and the digest expected for it.
+type Measurement struct {
+	Index     int    `json:"index"`               // PCR or measurement block index
+	Algorithm string `json:"algorithm,omitempty"` // Hash algorithm, e.g. SHA256
+	Digest    string `json:"digest,omitempty"`    // Hex encoded reference digest
+	URI       string `json:"uri,omitempty"`       // URI of the resource reporting the measurement
+}
+
+// TrustedModule is a trusted platform module (TPM) of a node.  Version 1
+// listed only the interface type, and a plain string decodes as that.
+// Redfish does not report EKCertificate or Measurements, so the redfish
+// mapper never sets them; they are for agents that read the TPM directly.
+type TrustedModule struct {
+	InterfaceType string        `json:"interface_type,omitempty"` // TPM interface type, e.g. TPM2_0 or TPM1_2
+	Firmware      string        `json:"firmware,omitempty"`       // Firmware version of the module
+	Status        string        `json:"status,omitempty"`         // State of the module, e.g. Enabled or Disabled
+	PCRBanks      []string      `json:"pcr_banks,omitempty"`      // Hash algorithms of the active PCR banks, e.g. SHA256
+	EKCertificate string        `json:"ek_certificate,omitempty"` // PEM encoded endorsement key certificate
+	Measurements  []Measurement `json:"measurements,omitempty"`   // Reference measurements for attestation
+}
+
+// TrustedComponent is a trusted component of a chassis, such as a root of
+// trust.  Version 1 listed only the URI, and a plain string decodes as that.
+// The redfish mapper does not set Certificates or Measurements.
+type TrustedComponent struct {
+	URI          string        `json:"uri,omitempty"`          // URI of the component
+	Type         string        `json:"type,omitempty"`         // Type of component, e.g. Discrete or Integrated
+	Manufacturer string        `json:"manufacturer,omitempty"` // Manufacturer of the component
+	Model        string        `json:"model,omitempty"`        // Model of the component
+	Serial       string        `json:"serial,omitempty"`       // Serial number of the component
+	UUID         string        `json:"uuid,omitempty"`         // UUID of the component
+	Firmware     string        `json:"firmware,omitempty"`     // Firmware version of the component
+	Status       string        `json:"status,omitempty"`       // State of the component, e.g. Enabled
+	Certificates []string      `json:"certificates,omitempty"` // PEM encoded identity certificates of the component
+	Measurements []Measurement `json:"measurements,omitempty"` // Reference measurements for attestation
+}
+
+func (m *TrustedModule) UnmarshalJSON(data []byte) error {
+	if s, ok := legacyString(data); ok {
+		*m = TrustedModule{InterfaceType: s}
+		return nil
+	}
+	type plain TrustedModule
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+func (c *TrustedComponent) UnmarshalJSON(data []byte) error {
+	if s, ok := legacyString(data); ok {
+		*c = TrustedComponent{URI: s}
+		return nil
+	}
+	type plain TrustedComponent
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// legacyString decodes data if it is a JSON string, the version 1 form of a
+// trusted module or component.
+func legacyString(data []byte) (string, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '"' {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// JSONSchemaExtend also accepts the version 1 interface type string.
+func (TrustedModule) JSONSchemaExtend(s *jsonschema.Schema) {
+	acceptLegacyString(s, "TPM interface type (version 1).  Deprecated: use an object")
+}
+
+// JSONSchemaExtend also accepts the version 1 URI string.
+func (TrustedComponent) JSONSchemaExtend(s *jsonschema.Schema) {
+	acceptLegacyString(s, "URI of the component (version 1).  Deprecated: use an object")
+}
+
+func acceptLegacyString(s *jsonschema.Schema, description string) {
+	object := *s
+	*s = jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			&object,
+			{Type: "string", Description: description, Deprecated: true},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package schemas
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+)
+
+func TestTrustedUnmarshal(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		modules    []TrustedModule
+		components []TrustedComponent
+		ok         bool
+	}{
+		{
+			name:       "version 1 strings",
+			in:         `{"trusted_modules": ["TPM2_0"], "trusted_components": ["/redfish/v1/Chassis/1/TrustedComponents/iLO"]}`,
+			modules:    []TrustedModule{{InterfaceType: "TPM2_0"}},
+			components: []TrustedComponent{{URI: "/redfish/v1/Chassis/1/TrustedComponents/iLO"}},
+			ok:         true,
+		},
+		{
+			name:       "objects",
+			in:         `{"trusted_modules": [{"interface_type": "TPM2_0", "firmware": "7.2", "pcr_banks": ["SHA256"]}], "trusted_components": [{"uri": "/TC/1", "type": "Discrete"}]}`,
+			modules:    []TrustedModule{{InterfaceType: "TPM2_0", Firmware: "7.2", PCRBanks: []string{"SHA256"}}},
+			components: []TrustedComponent{{URI: "/TC/1", Type: "Discrete"}},
+			ok:         true,
+		},
+		{
+			name:    "mixed",
+			in:      `{"trusted_modules": [ "TPM1_2" , {"interface_type": "TPM2_0"}]}`,
+			modules: []TrustedModule{{InterfaceType: "TPM1_2"}, {InterfaceType: "TPM2_0"}},
+			ok:      true,
+		},
+		{
+			name:    "escaped string",
+			in:      `{"trusted_modules": ["TPM2\u005f0"]}`,
+			modules: []TrustedModule{{InterfaceType: "TPM2_0"}},
+			ok:      true,
+		},
+		{name: "number", in: `{"trusted_modules": [2]}`},
+		{name: "wrong field type", in: `{"trusted_components": [{"uri": 1}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d InventoryDetail
+			err := json.Unmarshal([]byte(tt.in), &d)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !tt.ok {
+				return
+			}
+			if !reflect.DeepEqual(d.TrustedModules, tt.modules) || !reflect.DeepEqual(d.TrustedComponents, tt.components) {
+				t.Errorf("Unmarshal() = %+v and %+v, want %+v and %+v", d.TrustedModules, d.TrustedComponents, tt.modules, tt.components)
+			}
+		})
+	}
+}
+
+func TestTrustedMarshal(t *testing.T) {
+	b, err := json.Marshal(TrustedModule{InterfaceType: "TPM2_0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"interface_type":"TPM2_0"}` {
+		t.Errorf("Marshal() = %s, want an object", b)
+	}
+}
+
+func TestTrustedSchemaAcceptsString(t *testing.T) {
+	for _, v := range []any{&TrustedModule{}, &TrustedComponent{}} {
+		r := &jsonschema.Reflector{DoNotReference: true}
+		s := r.Reflect(v)
+		if len(s.OneOf) != 2 || s.OneOf[0].Type != "object" || s.OneOf[1].Type != "string" || !s.OneOf[1].Deprecated {
+			t.Errorf("schema of %T = %+v, want an object or a deprecated string", v, s)
+		}
+	}
+}